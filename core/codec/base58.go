@@ -0,0 +1,114 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"fmt"
+)
+
+// Base58BitcoinAlphabet is the base58 alphabet used by Bitcoin and IPFS, which excludes 0, O, I and l.
+const Base58BitcoinAlphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// Base58Encoding is a base58 encoding with the alphabet, the leading zero bytes are encoded as the first character.
+type Base58Encoding struct {
+	alphabet string
+	index    [256]int
+}
+
+// NewBase58Encoding returns a base58 encoding with the alphabet, which must be 58 unique ASCII characters.
+func NewBase58Encoding(alphabet string) *Base58Encoding {
+	if len(alphabet) != 58 {
+		panic("the base58 alphabet must be 58 characters")
+	}
+
+	e := &Base58Encoding{alphabet: alphabet}
+	for i := range e.index {
+		e.index[i] = -1
+	}
+	for i := 0; i < len(alphabet); i++ {
+		if e.index[alphabet[i]] != -1 {
+			panic("the base58 alphabet contains duplicate characters")
+		}
+		e.index[alphabet[i]] = i
+	}
+
+	return e
+}
+
+// EncodeToString returns the base58 string of the data.
+func (e *Base58Encoding) EncodeToString(data []byte) string {
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+
+	// log(256) / log(58) is about 1.37
+	digits := make([]byte, 0, (len(data)-zeros)*138/100+1)
+	for _, v := range data[zeros:] {
+		carry := int(v)
+		for i := range digits {
+			carry += int(digits[i]) << 8
+			digits[i] = byte(carry % 58)
+			carry /= 58
+		}
+		for carry > 0 {
+			digits = append(digits, byte(carry%58))
+			carry /= 58
+		}
+	}
+
+	result := make([]byte, zeros+len(digits))
+	for i := 0; i < zeros; i++ {
+		result[i] = e.alphabet[0]
+	}
+	for i, v := range digits {
+		result[len(result)-1-i] = e.alphabet[v]
+	}
+
+	return string(result)
+}
+
+// DecodeString returns the data of the base58 string.
+func (e *Base58Encoding) DecodeString(data string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(data) && data[zeros] == e.alphabet[0] {
+		zeros++
+	}
+
+	// log(58) / log(256) is about 0.74
+	bytes := make([]byte, 0, (len(data)-zeros)*74/100+1)
+	for i := zeros; i < len(data); i++ {
+		carry := e.index[data[i]]
+		if carry < 0 {
+			return nil, fmt.Errorf("illegal base58 data at input byte %d", i)
+		}
+		for j := range bytes {
+			carry += int(bytes[j]) * 58
+			bytes[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			bytes = append(bytes, byte(carry))
+			carry >>= 8
+		}
+	}
+
+	result := make([]byte, zeros+len(bytes))
+	for i, v := range bytes {
+		result[len(result)-1-i] = v
+	}
+
+	return result, nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBase58Encoding(t *testing.T) {
+	tests := []struct {
+		data    string
+		encoded string
+	}{
+		{data: "", encoded: ""},
+		{data: "Hello World!", encoded: "2NEpo7TZRRrLZSi2U"},
+		{data: "The quick brown fox jumps over the lazy dog.", encoded: "USm3fpXnKG5EUBx2ndxBDMPVciP5hGey2Jh4NDv6gmeo1LkMeiKrLJUUBk6Z"},
+		{data: "\x00\x00\x28\x7f\xb4\xcd", encoded: "11233QC4"},
+		{data: "\x00", encoded: "1"},
+	}
+
+	for _, v := range tests {
+		assert.Equal(t, v.encoded, Base58.EncodeToString([]byte(v.data)))
+
+		result, err := Base58.DecodeString(v.encoded)
+		assert.Nil(t, err)
+		assert.Equal(t, v.data, string(result))
+	}
+
+	_, err := Base58.DecodeString("0OIl")
+	assert.NotNil(t, err)
+
+	assert.Panics(t, func() { NewBase58Encoding("123") })
+	assert.Panics(t, func() { NewBase58Encoding(Base58BitcoinAlphabet[:57] + "1") })
+}
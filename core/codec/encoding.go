@@ -0,0 +1,62 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// Encoding encodes byte slices to strings and decodes them back, such as base64 and hex.
+// base64.Encoding and base32.Encoding implement it, so their custom variants can be used as well.
+type Encoding interface {
+	// EncodeToString returns the encoded string of the data.
+	EncodeToString(data []byte) string
+	// DecodeString returns the data of the encoded string.
+	DecodeString(data string) ([]byte, error)
+}
+
+var (
+	// Base64Std is the standard base64 encoding defined in RFC 4648.
+	Base64Std Encoding = base64.StdEncoding
+	// Base64URL is the URL-safe base64 encoding defined in RFC 4648.
+	Base64URL Encoding = base64.URLEncoding
+	// Base64RawStd is the standard base64 encoding without padding.
+	Base64RawStd Encoding = base64.RawStdEncoding
+	// Base64RawURL is the URL-safe base64 encoding without padding, which is usually used in URLs and tokens.
+	Base64RawURL Encoding = base64.RawURLEncoding
+	// Base32Std is the standard base32 encoding defined in RFC 4648.
+	Base32Std Encoding = base32.StdEncoding
+	// Base32Hex is the extended hex base32 encoding defined in RFC 4648.
+	Base32Hex Encoding = base32.HexEncoding
+	// Base58 is the base58 encoding with the Bitcoin alphabet.
+	Base58 Encoding = NewBase58Encoding(Base58BitcoinAlphabet)
+	// Hex is the lower case hex encoding.
+	Hex Encoding = hexEncoding{}
+)
+
+// hexEncoding is the hex encoding implemented by encoding/hex.
+type hexEncoding struct{}
+
+// EncodeToString returns the hex string of the data.
+func (hexEncoding) EncodeToString(data []byte) string {
+	return hex.EncodeToString(data)
+}
+
+// DecodeString returns the data of the hex string.
+func (hexEncoding) DecodeString(data string) ([]byte, error) {
+	return hex.DecodeString(data)
+}
@@ -0,0 +1,53 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncoding(t *testing.T) {
+	data := []byte{0xfb, 0xff, 0xbf, 0x00, 0x01}
+
+	tests := []struct {
+		name     string
+		encoding Encoding
+		encoded  string
+	}{
+		{name: "Base64Std", encoding: Base64Std, encoded: "+/+/AAE="},
+		{name: "Base64URL", encoding: Base64URL, encoded: "-_-_AAE="},
+		{name: "Base64RawStd", encoding: Base64RawStd, encoded: "+/+/AAE"},
+		{name: "Base64RawURL", encoding: Base64RawURL, encoded: "-_-_AAE"},
+		{name: "Base32Std", encoding: Base32Std, encoded: "7P736AAB"},
+		{name: "Base32Hex", encoding: Base32Hex, encoded: "VFVRU001"},
+		{name: "Base58", encoding: Base58, encoded: "VRzaLf2"},
+		{name: "Hex", encoding: Hex, encoded: "fbffbf0001"},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			assert.Equal(t, v.encoded, v.encoding.EncodeToString(data))
+
+			result, err := v.encoding.DecodeString(v.encoded)
+			assert.Nil(t, err)
+			assert.Equal(t, data, result)
+
+			_, err = v.encoding.DecodeString("!")
+			assert.NotNil(t, err)
+		})
+	}
+}
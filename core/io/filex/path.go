@@ -232,3 +232,42 @@ func AppendFileString(path, data string, perm int) error {
 		return err
 	}
 }
+
+// WriteFileAtomic writes a file atomically. The data is written by fn to a temporary file in the same directory
+// of path, and the temporary file is renamed to path after it is synced. The temporary file is removed if fn fails,
+// so the target file is never left partially written.
+func WriteFileAtomic(path string, perm int, fn func(f *os.File) error) (err error) {
+	if len(path) == 0 {
+		return errors.New("the path can not be empty")
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tmpFile.Close()
+			_ = os.Remove(tmpFile.Name())
+		}
+	}()
+
+	if err = fn(tmpFile); err != nil {
+		return err
+	}
+
+	if err = tmpFile.Chmod(fs.FileMode(perm)); err != nil {
+		return err
+	}
+
+	if err = tmpFile.Sync(); err != nil {
+		return err
+	}
+
+	if err = tmpFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile.Name(), path)
+}
@@ -271,3 +271,32 @@ func TestAppendFileString(t *testing.T) {
 	err = AppendFileString(filepath.Join(tmpDirPath, "2.txt"), "Hi!", SuperPerm)
 	assert.Nil(t, err)
 }
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "atomic.txt")
+
+	err := WriteFileAtomic(path, SuperReadWritePerm, func(f *os.File) error {
+		_, err := f.WriteString("hello")
+		return err
+	})
+	assert.Nil(t, err)
+
+	data, err := ReadFileString(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", data)
+
+	err = WriteFileAtomic(path, SuperReadWritePerm, func(f *os.File) error {
+		_, _ = f.WriteString("partial")
+		return fmt.Errorf("failed")
+	})
+	assert.NotNil(t, err)
+
+	data, err = ReadFileString(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", data)
+
+	files, err := GetFilesPathFromDir(dir, true)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"atomic.txt"}, files)
+}
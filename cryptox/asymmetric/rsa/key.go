@@ -21,6 +21,10 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"math/big"
+
+	"github.com/suyuan32/knife/core/codec"
+	"github.com/suyuan32/knife/cryptox/keyhandle"
 )
 
 var (
@@ -49,9 +53,90 @@ func (s *RSA) PrivateKeyFromPEM(data []byte) *RSA {
 		return s
 	}
 
+	return s.privateKeyFromDER(block.Bytes)
+}
+
+// PrivateKeyFromEncodedString gets private key from the DER data encoded by the encoding such as codec.Base64Std,
+// which is the PEM body without the header and footer.
+func (s *RSA) PrivateKeyFromEncodedString(data string, encoding codec.Encoding) *RSA {
+	result, err := encoding.DecodeString(data)
+	if err != nil {
+		s.Errors = errors.Join(s.Errors, err)
+		return s
+	}
+
+	return s.privateKeyFromDER(result)
+}
+
+// PrivateKeyFromHandle gets private key from the PEM data sealed in the key handle, the decoded DER data is zeroed
+// after parsing. The handle is not destroyed, it should be destroyed by the owner after use.
+func (s *RSA) PrivateKeyFromHandle(handle *keyhandle.Handle) *RSA {
+	err := handle.Use(func(key []byte) error {
+		block, _ := pem.Decode(key)
+		if block == nil {
+			return errorNotValidPEMKey
+		}
+		defer zero(block.Bytes)
+
+		s.privateKeyFromDER(block.Bytes)
+		return nil
+	})
+	s.Errors = errors.Join(s.Errors, err)
+	return s
+}
+
+// DestroyPrivateKey zeroes the private exponent, primes and precomputed values of the private key and removes it,
+// the public key is kept. The internal copies made by crypto/rsa for the CRT computation cannot be reached.
+func (s *RSA) DestroyPrivateKey() {
+	if s.PrivateKey == nil {
+		return
+	}
+
+	zeroInt(s.PrivateKey.D)
+	for _, v := range s.PrivateKey.Primes {
+		zeroInt(v)
+	}
+	zeroInt(s.PrivateKey.Precomputed.Dp)
+	zeroInt(s.PrivateKey.Precomputed.Dq)
+	zeroInt(s.PrivateKey.Precomputed.Qinv)
+	for _, v := range s.PrivateKey.Precomputed.CRTValues {
+		zeroInt(v.Exp)
+		zeroInt(v.Coeff)
+		zeroInt(v.R)
+	}
+
+	if s.PublicKey == nil {
+		publicKey := s.PrivateKey.PublicKey
+		s.PublicKey = &publicKey
+	}
+	s.PrivateKey = nil
+}
+
+// zeroInt sets the words of the big integer to zero.
+func zeroInt(n *big.Int) {
+	if n == nil {
+		return
+	}
+
+	words := n.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	n.SetInt64(0)
+}
+
+// zero sets all bytes of the data to zero.
+func zero(data []byte) {
+	for i := range data {
+		data[i] = 0
+	}
+}
+
+// privateKeyFromDER gets private key from the DER data encoded by PKCS1 or PKCS8.
+func (s *RSA) privateKeyFromDER(data []byte) *RSA {
 	switch s.Standard {
 	case PKCS1:
-		if parse, err := x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+		if parse, err := x509.ParsePKCS1PrivateKey(data); err != nil {
 			s.Errors = errors.Join(s.Errors, errorNotValidPrivateKey)
 			return s
 		} else {
@@ -59,11 +144,15 @@ func (s *RSA) PrivateKeyFromPEM(data []byte) *RSA {
 			return s
 		}
 	case PKCS8:
-		if parse, err := x509.ParsePKCS8PrivateKey(block.Bytes); err != nil {
+		if parse, err := x509.ParsePKCS8PrivateKey(data); err != nil {
+			s.Errors = errors.Join(s.Errors, errorNotValidPrivateKey)
+			return s
+		} else if privateKey, ok := parse.(*rsa.PrivateKey); !ok {
+			// PKCS8 also holds the keys of other algorithms such as ECDSA
 			s.Errors = errors.Join(s.Errors, errorNotValidPrivateKey)
 			return s
 		} else {
-			s.PrivateKey = parse.(*rsa.PrivateKey)
+			s.PrivateKey = privateKey
 			return s
 		}
 	default:
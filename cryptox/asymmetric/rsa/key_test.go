@@ -0,0 +1,158 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rsa
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/suyuan32/knife/core/codec"
+	"github.com/suyuan32/knife/cryptox/keyhandle"
+)
+
+func TestRSA_PrivateKeyFromHandle(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.Nil(t, err)
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	assert.Nil(t, err)
+
+	tests := []struct {
+		standard Standard
+		pemType  string
+		der      []byte
+	}{
+		{standard: PKCS1, pemType: "RSA PRIVATE KEY", der: x509.MarshalPKCS1PrivateKey(privateKey)},
+		{standard: PKCS8, pemType: "PRIVATE KEY", der: pkcs8},
+	}
+
+	for _, tt := range tests {
+		data := pem.EncodeToMemory(&pem.Block{Type: tt.pemType, Bytes: tt.der})
+		handle, err := keyhandle.Seal(data)
+		assert.Nil(t, err)
+		assert.Equal(t, make([]byte, len(data)), data)
+
+		s := &RSA{Standard: tt.standard}
+		s.PrivateKeyFromHandle(handle)
+		assert.Nil(t, s.Errors)
+		assert.True(t, privateKey.Equal(s.PrivateKey))
+
+		// the handle can be used again until it is destroyed
+		s = &RSA{Standard: tt.standard}
+		s.PrivateKeyFromHandle(handle)
+		assert.Nil(t, s.Errors)
+
+		handle.Destroy()
+		s = &RSA{Standard: tt.standard}
+		s.PrivateKeyFromHandle(handle)
+		assert.True(t, errors.Is(s.Errors, keyhandle.ErrDestroyed))
+		assert.Nil(t, s.PrivateKey)
+	}
+
+	handle, err := keyhandle.Seal([]byte("not a PEM key"))
+	assert.Nil(t, err)
+	s := &RSA{Standard: PKCS1}
+	s.PrivateKeyFromHandle(handle)
+	assert.True(t, errors.Is(s.Errors, errorNotValidPEMKey))
+}
+
+func TestRSA_PrivateKeyFromEncodedString(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.Nil(t, err)
+
+	s := &RSA{Standard: PKCS1}
+	s.PrivateKeyFromEncodedString(codec.Base64Std.EncodeToString(x509.MarshalPKCS1PrivateKey(privateKey)),
+		codec.Base64Std)
+	assert.Nil(t, s.Errors)
+	assert.True(t, privateKey.Equal(s.PrivateKey))
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	assert.Nil(t, err)
+	s = &RSA{Standard: PKCS8}
+	s.PrivateKeyFromEncodedString(codec.Hex.EncodeToString(pkcs8), codec.Hex)
+	assert.Nil(t, s.Errors)
+	assert.True(t, privateKey.Equal(s.PrivateKey))
+
+	s = &RSA{Standard: PKCS1}
+	s.PrivateKeyFromEncodedString("!", codec.Base64Std)
+	assert.NotNil(t, s.Errors)
+
+	s = &RSA{Standard: PKCS1}
+	s.PrivateKeyFromEncodedString(codec.Base64Std.EncodeToString(pkcs8), codec.Base64Std)
+	assert.True(t, errors.Is(s.Errors, errorNotValidPrivateKey))
+
+	// the PKCS8 key of other algorithms is rejected
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	ecPKCS8, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	assert.Nil(t, err)
+
+	s = &RSA{Standard: PKCS8}
+	s.PrivateKeyFromEncodedString(codec.Base64Std.EncodeToString(ecPKCS8), codec.Base64Std)
+	assert.True(t, errors.Is(s.Errors, errorNotValidPrivateKey))
+	assert.Nil(t, s.PrivateKey)
+
+	handle, err := keyhandle.Seal(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: ecPKCS8}))
+	assert.Nil(t, err)
+	s = &RSA{Standard: PKCS8}
+	s.PrivateKeyFromHandle(handle)
+	assert.True(t, errors.Is(s.Errors, errorNotValidPrivateKey))
+}
+
+func TestRSA_DestroyPrivateKey(t *testing.T) {
+	s := &RSA{Standard: PKCS1}
+	s.GenerateKeyPair(1024)
+	assert.Nil(t, s.Errors)
+
+	privateKey := s.PrivateKey
+	publicKey := privateKey.PublicKey
+	d := privateKey.D.Bits()
+	primes := [][]big.Word{privateKey.Primes[0].Bits(), privateKey.Primes[1].Bits()}
+
+	s.DestroyPrivateKey()
+	assert.Nil(t, s.PrivateKey)
+	assert.True(t, publicKey.Equal(s.PublicKey))
+
+	// the words of the private exponent and primes are zeroed
+	for _, words := range append(primes, d) {
+		for _, w := range words {
+			assert.Equal(t, big.Word(0), w)
+		}
+	}
+	assert.Equal(t, 0, privateKey.D.Sign())
+	assert.Equal(t, 0, privateKey.Precomputed.Dp.Sign())
+	assert.Equal(t, 0, privateKey.Precomputed.Qinv.Sign())
+
+	// the public key is kept when only the private key is set
+	s = &RSA{Standard: PKCS1}
+	s.GenerateKeyPair(1024)
+	s.PublicKey = nil
+	publicKey = s.PrivateKey.PublicKey
+	s.DestroyPrivateKey()
+	assert.True(t, publicKey.Equal(s.PublicKey))
+
+	// destroying without the private key does nothing
+	s.DestroyPrivateKey()
+	assert.True(t, publicKey.Equal(s.PublicKey))
+}
@@ -0,0 +1,202 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sm3 implements the SM3 hash algorithm defined in GB/T 32905-2016.
+package sm3
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+// Size is the size of an SM3 checksum in bytes.
+const Size = 32
+
+// BlockSize is the block size of SM3 in bytes.
+const BlockSize = 64
+
+const (
+	init0 = 0x7380166f
+	init1 = 0x4914b2b9
+	init2 = 0x172442d7
+	init3 = 0xda8a0600
+	init4 = 0xa96f30bc
+	init5 = 0x163138aa
+	init6 = 0xe38dee4d
+	init7 = 0xb0fb0e4e
+)
+
+// digest represents the partial evaluation of an SM3 checksum.
+type digest struct {
+	h   [8]uint32
+	x   [BlockSize]byte
+	nx  int
+	len uint64
+}
+
+// New returns a new hash.Hash computing the SM3 checksum.
+func New() hash.Hash {
+	d := new(digest)
+	d.Reset()
+	return d
+}
+
+// Sum returns the SM3 checksum of the data.
+func Sum(data []byte) [Size]byte {
+	d := new(digest)
+	d.Reset()
+	_, _ = d.Write(data)
+
+	var result [Size]byte
+	d.checkSum(result[:0])
+	return result
+}
+
+// Reset resets the hash to its initial state.
+func (d *digest) Reset() {
+	d.h = [8]uint32{init0, init1, init2, init3, init4, init5, init6, init7}
+	d.nx = 0
+	d.len = 0
+}
+
+// Size returns the number of bytes Sum will return.
+func (d *digest) Size() int {
+	return Size
+}
+
+// BlockSize returns the hash's underlying block size.
+func (d *digest) BlockSize() int {
+	return BlockSize
+}
+
+// Write adds more data to the running hash.
+func (d *digest) Write(p []byte) (int, error) {
+	n := len(p)
+	d.len += uint64(n)
+
+	if d.nx > 0 {
+		c := copy(d.x[d.nx:], p)
+		d.nx += c
+		if d.nx == BlockSize {
+			d.block(d.x[:])
+			d.nx = 0
+		}
+		p = p[c:]
+	}
+
+	if len(p) >= BlockSize {
+		m := len(p) &^ (BlockSize - 1)
+		d.block(p[:m])
+		p = p[m:]
+	}
+
+	if len(p) > 0 {
+		d.nx = copy(d.x[:], p)
+	}
+
+	return n, nil
+}
+
+// Sum appends the current hash to b and returns the resulting slice. It does not change the underlying hash state.
+func (d *digest) Sum(b []byte) []byte {
+	d0 := *d
+	return d0.checkSum(b)
+}
+
+// checkSum pads the message and appends the checksum to b.
+func (d *digest) checkSum(b []byte) []byte {
+	length := d.len
+
+	var tmp [BlockSize + 8]byte
+	tmp[0] = 0x80
+	if length%BlockSize < 56 {
+		_, _ = d.Write(tmp[0 : 56-length%BlockSize])
+	} else {
+		_, _ = d.Write(tmp[0 : BlockSize+56-length%BlockSize])
+	}
+
+	binary.BigEndian.PutUint64(tmp[:8], length<<3)
+	_, _ = d.Write(tmp[:8])
+
+	for _, v := range d.h {
+		b = binary.BigEndian.AppendUint32(b, v)
+	}
+	return b
+}
+
+func p0(x uint32) uint32 {
+	return x ^ bits.RotateLeft32(x, 9) ^ bits.RotateLeft32(x, 17)
+}
+
+func p1(x uint32) uint32 {
+	return x ^ bits.RotateLeft32(x, 15) ^ bits.RotateLeft32(x, 23)
+}
+
+// block runs the compression function over full blocks.
+func (d *digest) block(p []byte) {
+	var w [68]uint32
+	var w1 [64]uint32
+
+	for len(p) >= BlockSize {
+		for i := 0; i < 16; i++ {
+			w[i] = binary.BigEndian.Uint32(p[4*i:])
+		}
+		for i := 16; i < 68; i++ {
+			w[i] = p1(w[i-16]^w[i-9]^bits.RotateLeft32(w[i-3], 15)) ^ bits.RotateLeft32(w[i-13], 7) ^ w[i-6]
+		}
+		for i := 0; i < 64; i++ {
+			w1[i] = w[i] ^ w[i+4]
+		}
+
+		a, b, c, dd, e, f, g, h := d.h[0], d.h[1], d.h[2], d.h[3], d.h[4], d.h[5], d.h[6], d.h[7]
+		for i := 0; i < 64; i++ {
+			var t, ff, gg uint32
+			if i < 16 {
+				t = 0x79cc4519
+				ff = a ^ b ^ c
+				gg = e ^ f ^ g
+			} else {
+				t = 0x7a879d8a
+				ff = (a & b) | (a & c) | (b & c)
+				gg = (e & f) | (^e & g)
+			}
+
+			ss1 := bits.RotateLeft32(bits.RotateLeft32(a, 12)+e+bits.RotateLeft32(t, i%32), 7)
+			ss2 := ss1 ^ bits.RotateLeft32(a, 12)
+			tt1 := ff + dd + ss2 + w1[i]
+			tt2 := gg + h + ss1 + w[i]
+
+			dd = c
+			c = bits.RotateLeft32(b, 9)
+			b = a
+			a = tt1
+			h = g
+			g = bits.RotateLeft32(f, 19)
+			f = e
+			e = p0(tt2)
+		}
+
+		d.h[0] ^= a
+		d.h[1] ^= b
+		d.h[2] ^= c
+		d.h[3] ^= dd
+		d.h[4] ^= e
+		d.h[5] ^= f
+		d.h[6] ^= g
+		d.h[7] ^= h
+
+		p = p[BlockSize:]
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sm3
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSum(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{
+			name: "GB/T 32905 example 1",
+			data: []byte("abc"),
+			want: "66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0",
+		},
+		{
+			name: "GB/T 32905 example 2",
+			data: bytes.Repeat([]byte("abcd"), 16),
+			want: "debe9ff92275b8a138604889c18e5a4d6fdb70e5387e5765293dcba39c0c5732",
+		},
+		{
+			name: "empty",
+			data: nil,
+			want: "1ab21d8355cfa17f8e61194831e81a8f22bec8c728fefb747ed035eb5082aa2b",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sum := Sum(tt.data)
+			assert.Equal(t, tt.want, hex.EncodeToString(sum[:]))
+
+			// write byte by byte
+			h := New()
+			for _, b := range tt.data {
+				_, _ = h.Write([]byte{b})
+			}
+			assert.Equal(t, tt.want, hex.EncodeToString(h.Sum(nil)))
+
+			// sum does not change the state
+			assert.Equal(t, tt.want, hex.EncodeToString(h.Sum(nil)))
+
+			h.Reset()
+			_, _ = h.Write(tt.data)
+			assert.Equal(t, tt.want, hex.EncodeToString(h.Sum(nil)))
+		})
+	}
+
+	h := New()
+	assert.Equal(t, Size, h.Size())
+	assert.Equal(t, BlockSize, h.BlockSize())
+}
@@ -0,0 +1,163 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package keyhandle provides the opaque handle of key material. The key cannot be read from the handle directly,
+// it is redacted by fmt, JSON and text encodings, and it is zeroed by Destroy.
+package keyhandle
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrDestroyed is returned when the key of the handle has been destroyed.
+var ErrDestroyed = errors.New("the key handle has been destroyed")
+
+// redacted is the output of the handle in fmt, JSON and text encodings.
+const redacted = "[REDACTED]"
+
+// Handle is the opaque handle of key material, which can only be created by Seal and Generate.
+// The zero value is a destroyed handle. The copies of a handle share the same key, so Destroy of any copy
+// destroys the key.
+type Handle struct {
+	state *state
+}
+
+// state is the key material shared by the copies of a handle.
+type state struct {
+	mu  sync.RWMutex
+	key []byte
+}
+
+// Seal moves the key into a new handle, the key is copied and the given slice is zeroed.
+func Seal(key []byte) (*Handle, error) {
+	if len(key) == 0 {
+		return nil, errors.New("the key cannot be empty")
+	}
+
+	h := &Handle{state: &state{key: make([]byte, len(key))}}
+	copy(h.state.key, key)
+	zero(key)
+
+	return h, nil
+}
+
+// Generate returns a handle of a random key with the size generated by crypto/rand.
+func Generate(size int) (*Handle, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("the key size must be positive, got %d", size)
+	}
+
+	key := make([]byte, size)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate key, error:%s", err)
+	}
+
+	return &Handle{state: &state{key: key}}, nil
+}
+
+// Use calls fn with the key, the key must not be modified or retained after fn returns.
+// It returns ErrDestroyed if the key has been destroyed.
+func (h *Handle) Use(fn func(key []byte) error) error {
+	if h == nil || h.state == nil {
+		return ErrDestroyed
+	}
+
+	h.state.mu.RLock()
+	defer h.state.mu.RUnlock()
+
+	if h.state.key == nil {
+		return ErrDestroyed
+	}
+
+	return fn(h.state.key)
+}
+
+// Len returns the size of the key, it returns 0 if the key has been destroyed.
+func (h *Handle) Len() int {
+	if h == nil || h.state == nil {
+		return 0
+	}
+
+	h.state.mu.RLock()
+	defer h.state.mu.RUnlock()
+
+	return len(h.state.key)
+}
+
+// Destroy zeroes the key, the handle cannot be used any more. It waits for the running Use calls.
+func (h *Handle) Destroy() {
+	if h == nil || h.state == nil {
+		return
+	}
+
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+
+	zero(h.state.key)
+	h.state.key = nil
+}
+
+// Destroyed returns true if the key has been destroyed.
+func (h *Handle) Destroyed() bool {
+	return h.Len() == 0
+}
+
+// String returns the redacted key.
+func (h Handle) String() string {
+	return redacted
+}
+
+// GoString returns the redacted key for %#v.
+func (h Handle) GoString() string {
+	return "keyhandle.Handle(" + redacted + ")"
+}
+
+// Format prints the redacted key for all verbs such as %x, so the key cannot be leaked by fmt.
+func (h Handle) Format(f fmt.State, verb rune) {
+	if verb == 'v' && f.Flag('#') {
+		_, _ = fmt.Fprint(f, h.GoString())
+		return
+	}
+	_, _ = fmt.Fprint(f, redacted)
+}
+
+// MarshalJSON returns the redacted key as a JSON string.
+func (h Handle) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + redacted + `"`), nil
+}
+
+// MarshalText returns the redacted key.
+func (h Handle) MarshalText() ([]byte, error) {
+	return []byte(redacted), nil
+}
+
+// UnmarshalJSON always returns an error, because the handle can only be created by Seal and Generate.
+func (h *Handle) UnmarshalJSON([]byte) error {
+	return errors.New("the key handle cannot be unmarshalled")
+}
+
+// UnmarshalText always returns an error, because the handle can only be created by Seal and Generate.
+func (h *Handle) UnmarshalText([]byte) error {
+	return errors.New("the key handle cannot be unmarshalled")
+}
+
+// zero sets all bytes of the data to zero.
+func zero(data []byte) {
+	for i := range data {
+		data[i] = 0
+	}
+}
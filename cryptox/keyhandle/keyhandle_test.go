@@ -0,0 +1,100 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyhandle
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeal(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	h, err := Seal(key)
+	assert.Nil(t, err)
+	assert.Equal(t, make([]byte, 16), key)
+	assert.Equal(t, 16, h.Len())
+
+	err = h.Use(func(key []byte) error {
+		assert.Equal(t, []byte("0123456789abcdef"), key)
+		return nil
+	})
+	assert.Nil(t, err)
+
+	_, err = Seal(nil)
+	assert.NotNil(t, err)
+}
+
+func TestGenerate(t *testing.T) {
+	h, err := Generate(32)
+	assert.Nil(t, err)
+	assert.Equal(t, 32, h.Len())
+	assert.False(t, h.Destroyed())
+
+	_, err = Generate(0)
+	assert.NotNil(t, err)
+}
+
+func TestHandle_Destroy(t *testing.T) {
+	h, err := Seal(bytes.Repeat([]byte{'a'}, 16))
+	assert.Nil(t, err)
+
+	var inner []byte
+	assert.Nil(t, h.Use(func(key []byte) error {
+		inner = key
+		return nil
+	}))
+
+	h.Destroy()
+	assert.True(t, h.Destroyed())
+	assert.Equal(t, make([]byte, 16), inner)
+	assert.Equal(t, 0, h.Len())
+	assert.ErrorIs(t, h.Use(func([]byte) error { return nil }), ErrDestroyed)
+
+	// destroying twice is safe
+	h.Destroy()
+
+	var empty Handle
+	assert.True(t, empty.Destroyed())
+	assert.ErrorIs(t, empty.Use(func([]byte) error { return nil }), ErrDestroyed)
+
+	var nilHandle *Handle
+	assert.True(t, nilHandle.Destroyed())
+}
+
+func TestHandle_Redacted(t *testing.T) {
+	h, err := Seal([]byte("secret-key-bytes"))
+	assert.Nil(t, err)
+
+	for _, format := range []string{"%v", "%+v", "%s", "%x", "%q", "%#v"} {
+		assert.NotContains(t, fmt.Sprintf(format, h), "secret")
+		assert.NotContains(t, fmt.Sprintf(format, *h), "secret")
+		assert.Contains(t, fmt.Sprintf(format, h), "REDACTED")
+	}
+
+	data, err := json.Marshal(struct {
+		Key *Handle `json:"key"`
+	}{Key: h})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"key":"[REDACTED]"}`, string(data))
+
+	var v struct {
+		Key *Handle `json:"key"`
+	}
+	assert.NotNil(t, json.Unmarshal(data, &v))
+}
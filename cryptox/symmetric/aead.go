@@ -0,0 +1,167 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"crypto/cipher"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/suyuan32/knife/cryptox/symmetric/method"
+	"github.com/suyuan32/knife/cryptox/symmetric/mode"
+)
+
+// gcmStandardNonceSize is the nonce size recommended by NIST SP 800-38D.
+const gcmStandardNonceSize = 12
+
+// ErrAuthenticationFailed is returned by Decrypt when the authentication tag does not match,
+// which means the key, nonce or associated data is wrong, or the ciphertext has been tampered with.
+var ErrAuthenticationFailed = errors.New("message authentication failed")
+
+// isAEAD returns true if the CryptoS uses an authenticated encryption method or mode.
+func (s *CryptoS) isAEAD() bool {
+	return s.Method == method.ChaCha20Poly1305 || s.Method == method.XChaCha20Poly1305 ||
+		s.Mode == mode.GCM || s.Mode == mode.CCM || s.Mode == mode.SIV
+}
+
+// nonceSize returns the nonce size of AEAD methods and modes.
+func (s *CryptoS) nonceSize() int {
+	switch s.Method {
+	case method.ChaCha20Poly1305:
+		return chacha20poly1305.NonceSize
+	case method.XChaCha20Poly1305:
+		return chacha20poly1305.NonceSizeX
+	}
+
+	// the nonce of SIV mode is optional
+	if s.Mode == mode.SIV || s.NonceSize != 0 {
+		return s.NonceSize
+	}
+	return gcmStandardNonceSize
+}
+
+// tagSize returns the tag size of the CCM mode.
+func (s *CryptoS) tagSize() int {
+	if s.TagSize != 0 {
+		return s.TagSize
+	}
+	return ccmDefaultTagSize
+}
+
+// NewAEAD returns an AEAD cipher from the cryptos for authenticated methods such as ChaCha20-Poly1305 and
+// authenticated modes such as GCM, CCM and SIV.
+func (s *CryptoS) NewAEAD() (aead cipher.AEAD, err error) {
+	s.operate(func() { aead, err = s.newAEAD() })
+	return aead, err
+}
+
+// newAEAD returns an AEAD cipher from the cryptos in an operation.
+func (s *CryptoS) newAEAD() (cipher.AEAD, error) {
+	switch s.Method {
+	case method.ChaCha20Poly1305:
+		return chacha20poly1305.New(s.key())
+	case method.XChaCha20Poly1305:
+		return chacha20poly1305.NewX(s.key())
+	}
+
+	switch s.Mode {
+	case mode.GCM:
+		block, err := s.NewCipher()
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCMWithNonceSize(block, s.nonceSize())
+	case mode.CCM:
+		block, err := s.NewCipher()
+		if err != nil {
+			return nil, err
+		}
+		return newCCM(block, s.nonceSize(), s.tagSize())
+	case mode.SIV:
+		key := s.key()
+		macBlock, err := s.newBlock(key[:len(key)/2])
+		if err != nil {
+			return nil, err
+		}
+		ctrBlock, err := s.newBlock(key[len(key)/2:])
+		if err != nil {
+			return nil, err
+		}
+		return newSIV(macBlock, ctrBlock, s.nonceSize())
+	}
+
+	return nil, errors.New("the mode is not an AEAD mode")
+}
+
+// sealAEAD encrypts and authenticates the input data, the tag is appended to the output data.
+func (s *CryptoS) sealAEAD() *CryptoS {
+	if s.RandomIV {
+		if err := s.generateIV(s.nonceSize()); err != nil {
+			s.Errors = errors.Join(s.Errors, err)
+			return s
+		}
+	}
+
+	err := s.validateAEAD()
+	if err != nil {
+		s.Errors = errors.Join(s.Errors, fmt.Errorf("failed to validate data, error:%w", err))
+		return s
+	}
+
+	aead, err := s.NewAEAD()
+	if err != nil {
+		s.Errors = errors.Join(s.Errors, fmt.Errorf("failed to create cipher from the data, error:%s", err))
+		return s
+	}
+
+	s.OutputData = aead.Seal(nil, s.IV, s.InputData, s.AdditionalData)
+	s.prependIV()
+
+	return s
+}
+
+// openAEAD decrypts and authenticates the input data which has the tag at the end.
+func (s *CryptoS) openAEAD() *CryptoS {
+	if s.RandomIV {
+		if err := s.splitIV(s.nonceSize()); err != nil {
+			s.Errors = errors.Join(s.Errors, err)
+			return s
+		}
+	}
+
+	err := s.validateAEAD()
+	if err != nil {
+		s.Errors = errors.Join(s.Errors, fmt.Errorf("failed to validate data, error:%w", err))
+		return s
+	}
+
+	aead, err := s.NewAEAD()
+	if err != nil {
+		s.Errors = errors.Join(s.Errors, fmt.Errorf("failed to create cipher from the data, error:%s", err))
+		return s
+	}
+
+	result, err := aead.Open(nil, s.IV, s.InputData, s.AdditionalData)
+	if err != nil {
+		s.Errors = errors.Join(s.Errors, fmt.Errorf("failed to decrypt data, error:%w", ErrAuthenticationFailed))
+		return s
+	}
+
+	s.OutputData = result
+
+	return s
+}
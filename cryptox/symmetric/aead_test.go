@@ -0,0 +1,256 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/suyuan32/knife/cryptox/symmetric/method"
+	"github.com/suyuan32/knife/cryptox/symmetric/mode"
+)
+
+func TestCryptoS_GCM(t *testing.T) {
+	testStr := bytes.Repeat([]byte{'a'}, 120)
+	for _, v := range []method.MethodType{method.AES, method.SM4, method.Twofish} {
+		for _, nonceSize := range []int{0, 12, 16} {
+			iv := bytes.Repeat([]byte{'b'}, 12)
+			if nonceSize != 0 {
+				iv = bytes.Repeat([]byte{'b'}, nonceSize)
+			}
+
+			c := NewCryptoS()
+			result, err := c.InputFromBytes(testStr).
+				WithMethod(v).
+				WithMode(mode.GCM).
+				WithNonceSize(nonceSize).
+				WithIV(iv).
+				WithKey(bytes.Repeat([]byte{'c'}, 16)).
+				WithAdditionalData([]byte("header")).
+				Encrypt().
+				ToBytes()
+
+			assert.Nil(t, err)
+			assert.Equal(t, len(testStr)+16, len(result))
+
+			c = NewCryptoS()
+			decryptResult, err := c.InputFromBytes(result).
+				WithMethod(v).
+				WithMode(mode.GCM).
+				WithNonceSize(nonceSize).
+				WithIV(iv).
+				WithKey(bytes.Repeat([]byte{'c'}, 16)).
+				WithAdditionalData([]byte("header")).
+				Decrypt().
+				ToBytes()
+
+			assert.Nil(t, err)
+			assert.Equal(t, testStr, decryptResult)
+
+			// wrong associated data
+			c = NewCryptoS()
+			_, err = c.InputFromBytes(result).
+				WithMethod(v).
+				WithMode(mode.GCM).
+				WithNonceSize(nonceSize).
+				WithIV(iv).
+				WithKey(bytes.Repeat([]byte{'c'}, 16)).
+				WithAdditionalData([]byte("footer")).
+				Decrypt().
+				ToBytes()
+
+			assert.True(t, errors.Is(err, ErrAuthenticationFailed))
+
+			// tampered ciphertext
+			result[0] ^= 1
+			c = NewCryptoS()
+			_, err = c.InputFromBytes(result).
+				WithMethod(v).
+				WithMode(mode.GCM).
+				WithNonceSize(nonceSize).
+				WithIV(iv).
+				WithKey(bytes.Repeat([]byte{'c'}, 16)).
+				WithAdditionalData([]byte("header")).
+				Decrypt().
+				ToBytes()
+
+			assert.True(t, errors.Is(err, ErrAuthenticationFailed))
+		}
+	}
+}
+
+func TestCryptoS_GCM_KnownAnswer(t *testing.T) {
+	tests := []struct {
+		name   string
+		method method.MethodType
+		key    string
+		iv     string
+		aad    string
+		input  string
+		want   string
+	}{
+		{
+			name:   "AES-128-GCM test case 2",
+			method: method.AES,
+			key:    "00000000000000000000000000000000",
+			iv:     "000000000000000000000000",
+			input:  "00000000000000000000000000000000",
+			want:   "0388dace60b6a392f328c2b971b2fe78ab6e47d42cec13bdf53a67b21257bddf",
+		},
+		{
+			name:   "ChaCha20-Poly1305 RFC 8439",
+			method: method.ChaCha20Poly1305,
+			key:    "808182838485868788898a8b8c8d8e8f909192939495969798999a9b9c9d9e9f",
+			iv:     "070000004041424344454647",
+			aad:    "50515253c0c1c2c3c4c5c6c7",
+			input: "4c616469657320616e642047656e746c656d656e206f662074686520636c6173" +
+				"73206f66202739393a204966204920636f756c64206f6666657220796f75206f" +
+				"6e6c79206f6e652074697020666f7220746865206675747572652c2073756e73" +
+				"637265656e20776f756c642062652069742e",
+			want: "d31a8d34648e60db7b86afbc53ef7ec2a4aded51296e08fea9e2b5a736ee62d6" +
+				"3dbea45e8ca9671282fafb69da92728b1a71de0a9e060b2905d6a5b67ecd3b36" +
+				"92ddbd7f2d778b8c9803aee328091b58fab324e4fad675945585808b4831d7bc" +
+				"3ff4def08e4b7a9de576d26586cec64b6116" +
+				"1ae10b594f09e26a7e902ecbd0600691",
+		},
+		{
+			name:   "SM4-GCM RFC 8998",
+			method: method.SM4,
+			key:    "0123456789abcdeffedcba9876543210",
+			iv:     "00001234567800000000abcd",
+			aad:    "feedfacedeadbeeffeedfacedeadbeefabaddad2",
+			input: "aaaaaaaaaaaaaaaabbbbbbbbbbbbbbbbccccccccccccccccdddddddddddddddd" +
+				"eeeeeeeeeeeeeeeeffffffffffffffffeeeeeeeeeeeeeeeeaaaaaaaaaaaaaaaa",
+			want: "17f399f08c67d5ee19d0dc9969c4bb7d5fd46fd3756489069157b282bb200735" +
+				"d82710ca5c22f0ccfa7cbf93d496ac15a56834cbcf98c397b4024a2691233b8d" +
+				"83de3541e4c2b58177e065a9bf7b62ec",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aad, _ := hex.DecodeString(tt.aad)
+			c := NewCryptoS()
+			result, err := c.InputFromHexString(tt.input).
+				WithMethod(tt.method).
+				WithMode(mode.GCM).
+				IVFromHexString(tt.iv).
+				KeyFromHexString(tt.key).
+				WithAdditionalData(aad).
+				Encrypt().
+				ToHexString()
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, result)
+		})
+	}
+}
+
+func TestCryptoS_GCM_Validate(t *testing.T) {
+	c := NewCryptoS()
+	c.InputFromString("hello").
+		WithMode(mode.GCM).
+		WithIV(bytes.Repeat([]byte{'b'}, 16)).
+		WithKey(bytes.Repeat([]byte{'c'}, 16)).
+		Encrypt()
+	assert.NotNil(t, c.Errors)
+
+	// GCM requires 128-bit block cipher
+	c = NewCryptoS()
+	c.InputFromString("hello").
+		WithMethod(method.XTEA).
+		WithMode(mode.GCM).
+		WithIV(bytes.Repeat([]byte{'b'}, 12)).
+		WithKey(bytes.Repeat([]byte{'c'}, 16)).
+		Encrypt()
+	assert.NotNil(t, c.Errors)
+
+	c = NewCryptoS()
+	c.InputFromString("hello").
+		WithMethod(method.XTEA).
+		WithMode(mode.GCM).
+		WithIV(bytes.Repeat([]byte{'b'}, 12)).
+		WithKey(bytes.Repeat([]byte{'c'}, 16)).
+		Decrypt()
+	assert.NotNil(t, c.Errors)
+}
+
+func TestCryptoS_ChaCha20Poly1305(t *testing.T) {
+	testStr := bytes.Repeat([]byte{'a'}, 120)
+	for _, v := range []method.MethodType{method.ChaCha20Poly1305, method.XChaCha20Poly1305} {
+		nonceSize := 12
+		if v == method.XChaCha20Poly1305 {
+			nonceSize = 24
+		}
+
+		c := NewCryptoS()
+		result, err := c.InputFromBytes(testStr).
+			WithMethod(v).
+			WithIV(bytes.Repeat([]byte{'b'}, nonceSize)).
+			WithKey(bytes.Repeat([]byte{'c'}, 32)).
+			WithAdditionalData([]byte("header")).
+			Encrypt().
+			ToBytes()
+
+		assert.Nil(t, err)
+		assert.Equal(t, len(testStr)+16, len(result))
+
+		c = NewCryptoS()
+		decryptResult, err := c.InputFromBytes(result).
+			WithMethod(v).
+			WithIV(bytes.Repeat([]byte{'b'}, nonceSize)).
+			WithKey(bytes.Repeat([]byte{'c'}, 32)).
+			WithAdditionalData([]byte("header")).
+			Decrypt().
+			ToBytes()
+
+		assert.Nil(t, err)
+		assert.Equal(t, testStr, decryptResult)
+
+		// tampered ciphertext
+		result[len(result)-1] ^= 1
+		c = NewCryptoS()
+		_, err = c.InputFromBytes(result).
+			WithMethod(v).
+			WithIV(bytes.Repeat([]byte{'b'}, nonceSize)).
+			WithKey(bytes.Repeat([]byte{'c'}, 32)).
+			WithAdditionalData([]byte("header")).
+			Decrypt().
+			ToBytes()
+
+		assert.True(t, errors.Is(err, ErrAuthenticationFailed))
+
+		// wrong nonce size
+		c = NewCryptoS()
+		c.InputFromBytes(testStr).
+			WithMethod(v).
+			WithIV(bytes.Repeat([]byte{'b'}, nonceSize+1)).
+			WithKey(bytes.Repeat([]byte{'c'}, 32)).
+			Encrypt()
+		assert.NotNil(t, c.Errors)
+
+		// wrong key size
+		c = NewCryptoS()
+		c.InputFromBytes(testStr).
+			WithMethod(v).
+			WithIV(bytes.Repeat([]byte{'b'}, nonceSize)).
+			WithKey(bytes.Repeat([]byte{'c'}, 16)).
+			Decrypt()
+		assert.NotNil(t, c.Errors)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/suyuan32/knife/cryptox/symmetric/mode"
+)
+
+// Cipher is an immutable and validated symmetric cipher built from CryptoS. It is safe to be shared and called
+// concurrently by multiple goroutines.
+type Cipher struct {
+	config CryptoS
+}
+
+// Build validates the configuration of CryptoS and returns an immutable Cipher. The key, IV and associated data are
+// copied, so later changes of CryptoS do not affect the Cipher. The nonce-based AEAD needs RandomIV because the
+// nonce cannot be reused, the SIV mode is resistant to nonce reuse and can be built without it.
+func (s *CryptoS) Build() (*Cipher, error) {
+	if s.Errors != nil {
+		return nil, s.Errors
+	}
+
+	// the ciphertext of Cipher has no key ID, use ToEnvelope of CryptoS instead
+	if s.Keyring != nil {
+		return nil, errors.New("the keyring is not supported by Cipher")
+	}
+
+	config := CryptoS{
+		Key:             bytes.Clone(s.Key),
+		KeyHandle:       s.KeyHandle,
+		IV:              bytes.Clone(s.IV),
+		Method:          s.Method,
+		Mode:            s.Mode,
+		Padding:         s.Padding,
+		AdditionalData:  bytes.Clone(s.AdditionalData),
+		NonceSize:       s.NonceSize,
+		TagSize:         s.TagSize,
+		MAC:             s.MAC,
+		RandomIV:        s.RandomIV,
+		KeyID:           s.KeyID,
+		ECBAllowed:      s.ECBAllowed,
+		InsecureAllowed: s.InsecureAllowed,
+	}
+
+	// the Cipher is reused for many messages, a fixed nonce would be reused by every encryption
+	if config.isAEAD() && config.Mode != mode.SIV && !config.RandomIV {
+		return nil, fmt.Errorf("failed to validate data, error:%w",
+			newValidationError("IV", "the fixed nonce cannot be used by the AEAD Cipher, call WithRandomIV instead"))
+	}
+
+	if s.KDF != nil {
+		params := *s.KDF
+		params.Salt = bytes.Clone(s.KDF.Salt)
+		config.KDF = &params
+	}
+
+	if err := config.validateConfig(); err != nil {
+		return nil, fmt.Errorf("failed to validate data, error:%w", err)
+	}
+
+	return &Cipher{config: config}, nil
+}
+
+// validateConfig validates the configuration without input data. A placeholder IV is used if the IV is random.
+func (s *CryptoS) validateConfig() (err error) {
+	s.operate(func() { err = s.checkConfig() })
+	return err
+}
+
+// checkConfig validates the configuration in the operation started by validateConfig.
+func (s *CryptoS) checkConfig() error {
+	if s.RandomIV {
+		s.IV = make([]byte, s.ivSize(s.blockSize()))
+		defer func() { s.IV = nil }()
+	}
+
+	if err := s.Validate(s.blockSize()); err != nil {
+		return err
+	}
+
+	if s.isAEAD() {
+		_, err := s.NewAEAD()
+		return err
+	}
+
+	_, _, err := s.newBlockCipher()
+	return err
+}
+
+// Encrypt encrypts the plaintext and returns the ciphertext.
+func (c *Cipher) Encrypt(plaintext []byte) ([]byte, error) {
+	s := c.config
+	s.InputData = bytes.Clone(plaintext)
+
+	if s.Encrypt(); s.Errors != nil {
+		return nil, s.Errors
+	}
+
+	return s.OutputData, nil
+}
+
+// Decrypt decrypts the ciphertext and returns the plaintext.
+func (c *Cipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	s := c.config
+	s.InputData = ciphertext
+
+	if s.Decrypt(); s.Errors != nil {
+		return nil, s.Errors
+	}
+
+	return s.OutputData, nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/suyuan32/knife/cryptox/symmetric/method"
+	"github.com/suyuan32/knife/cryptox/symmetric/mode"
+	"github.com/suyuan32/knife/cryptox/symmetric/padding"
+)
+
+func TestCryptoS_Build(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  method.MethodType
+		mode    mode.ModeType
+		keySize int
+	}{
+		{name: "AES-CBC", method: method.AES, mode: mode.CBC, keySize: 32},
+		{name: "SM4-CTR", method: method.SM4, mode: mode.CTR, keySize: 16},
+		{name: "AES-GCM", method: method.AES, mode: mode.GCM, keySize: 16},
+		{name: "ChaCha20-Poly1305", method: method.ChaCha20Poly1305, keySize: 32},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewCryptoS()
+			c, err := s.WithMethod(tt.method).WithMode(tt.mode).WithPadding(padding.PKCS7).WithRandomIV().
+				KeyFromBytes(bytes.Repeat([]byte{'k'}, tt.keySize)).Build()
+			if !assert.Nil(t, err) {
+				return
+			}
+
+			// changes of CryptoS do not affect the built cipher
+			s.Key[0] = 'x'
+			s.WithMethod(method.TEA)
+
+			var wg sync.WaitGroup
+			for i := 0; i < 16; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+
+					plaintext := bytes.Repeat([]byte{byte(i)}, 10+i*7)
+					ciphertext, err := c.Encrypt(plaintext)
+					assert.Nil(t, err)
+
+					result, err := c.Decrypt(ciphertext)
+					assert.Nil(t, err)
+					assert.Equal(t, plaintext, result)
+				}(i)
+			}
+			wg.Wait()
+		})
+	}
+}
+
+func TestCryptoS_Build_Invalid(t *testing.T) {
+	s := NewCryptoS()
+	_, err := s.KeyFromString("short").WithRandomIV().Build()
+	assert.NotNil(t, err)
+
+	s = NewCryptoS()
+	_, err = s.KeyFromString("1234567890123456").IVFromString("short").Build()
+	assert.NotNil(t, err)
+
+	s = NewCryptoS()
+	_, err = s.WithMode(mode.ECB).KeyFromString("1234567890123456").Build()
+	assert.NotNil(t, err)
+
+	s = NewCryptoS()
+	c, err := s.WithPadding(padding.PKCS7).KeyFromString("1234567890123456").IVFromString("1234567890123456").Build()
+	assert.Nil(t, err)
+
+	_, err = c.Decrypt(nil)
+	assert.NotNil(t, err)
+
+	_, err = c.Decrypt([]byte("abcdefghijklmnop"))
+	assert.True(t, errors.Is(err, padding.ErrInvalidPadding))
+}
+
+func TestCryptoS_Build_FixedNonce(t *testing.T) {
+	tests := []struct {
+		name   string
+		method method.MethodType
+		mode   mode.ModeType
+		key    []byte
+		iv     []byte
+	}{
+		{name: "AES-GCM", method: method.AES, mode: mode.GCM, key: bytes.Repeat([]byte{'k'}, 16), iv: bytes.Repeat([]byte{'n'}, 12)},
+		{name: "AES-CCM", method: method.AES, mode: mode.CCM, key: bytes.Repeat([]byte{'k'}, 16), iv: bytes.Repeat([]byte{'n'}, 12)},
+		{name: "ChaCha20-Poly1305", method: method.ChaCha20Poly1305, key: bytes.Repeat([]byte{'k'}, 32), iv: bytes.Repeat([]byte{'n'}, 12)},
+		{name: "XChaCha20-Poly1305", method: method.XChaCha20Poly1305, key: bytes.Repeat([]byte{'k'}, 32), iv: bytes.Repeat([]byte{'n'}, 24)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewCryptoS()
+			_, err := s.WithMethod(tt.method).WithMode(tt.mode).WithKey(tt.key).WithIV(tt.iv).Build()
+			var validationErr *ValidationError
+			if assert.True(t, errors.As(err, &validationErr)) {
+				assert.Equal(t, "IV", validationErr.Field)
+			}
+
+			// the random nonce is generated for every encryption
+			s = NewCryptoS()
+			c, err := s.WithMethod(tt.method).WithMode(tt.mode).WithKey(tt.key).WithRandomIV().Build()
+			if !assert.Nil(t, err) {
+				return
+			}
+
+			first, err := c.Encrypt([]byte("hello"))
+			assert.Nil(t, err)
+			second, err := c.Encrypt([]byte("hello"))
+			assert.Nil(t, err)
+			assert.NotEqual(t, first, second)
+		})
+	}
+}
+
+func TestCryptoS_Reset(t *testing.T) {
+	s := NewCryptoS()
+	s.WithMethod(method.SM4).WithMode(mode.CTR).WithPadding(padding.PKCS7).WithRandomIV().
+		KeyFromString("1234567890123456").InputFromString("hello").Encrypt()
+	assert.NotNil(t, s.OutputData)
+
+	s.Reset()
+	assert.Equal(t, NewCryptoS(), s)
+}
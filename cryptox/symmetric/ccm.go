@@ -0,0 +1,204 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+const (
+	// ccmBlockSize is the block size required by the CCM mode.
+	ccmBlockSize = 16
+	// ccmDefaultTagSize is the tag size used by the CCM mode if TagSize is zero.
+	ccmDefaultTagSize = 16
+	// ccmMinNonceSize and ccmMaxNonceSize are the nonce size range of the CCM mode.
+	ccmMinNonceSize = 7
+	ccmMaxNonceSize = 13
+)
+
+// ccm implements the CCM mode (Counter with CBC-MAC) defined in NIST SP 800-38C and RFC 3610.
+type ccm struct {
+	block     cipher.Block
+	nonceSize int
+	tagSize   int
+}
+
+// newCCM returns the CCM mode of a 16 bytes block cipher with the nonce size and tag size.
+func newCCM(block cipher.Block, nonceSize, tagSize int) (cipher.AEAD, error) {
+	if block.BlockSize() != ccmBlockSize {
+		return nil, errors.New("the CCM mode requires a 16 bytes block cipher")
+	}
+
+	if nonceSize < ccmMinNonceSize || nonceSize > ccmMaxNonceSize {
+		return nil, errors.New("the nonce size of CCM mode must be between 7 and 13")
+	}
+
+	if !isValidCCMTagSize(tagSize) {
+		return nil, errors.New("the tag size of CCM mode must be 4, 6, 8, 10, 12, 14 or 16")
+	}
+
+	return &ccm{block: block, nonceSize: nonceSize, tagSize: tagSize}, nil
+}
+
+// isValidCCMTagSize returns true if the tag size is allowed by the CCM mode.
+func isValidCCMTagSize(size int) bool {
+	return size >= 4 && size <= 16 && size%2 == 0
+}
+
+// NonceSize returns the size of the nonce.
+func (c *ccm) NonceSize() int {
+	return c.nonceSize
+}
+
+// Overhead returns the size of the tag.
+func (c *ccm) Overhead() int {
+	return c.tagSize
+}
+
+// maxLength returns the max length of the message limited by the length field.
+func (c *ccm) maxLength() uint64 {
+	q := 15 - c.nonceSize
+	if q >= 8 {
+		return math.MaxInt
+	}
+	return 1<<(8*q) - 1
+}
+
+// Seal encrypts and authenticates the plaintext, authenticates the additional data and appends the result to dst.
+func (c *ccm) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != c.nonceSize {
+		panic("crypto/cipher: incorrect nonce length given to CCM")
+	}
+	if uint64(len(plaintext)) > c.maxLength() {
+		panic("crypto/cipher: message too large for CCM")
+	}
+
+	ret, out := sliceForAppend(dst, len(plaintext)+c.tagSize)
+
+	tag := c.tag(nonce, plaintext, additionalData)
+	c.crypt(nonce, out[:len(plaintext)], plaintext)
+	copy(out[len(plaintext):], tag)
+
+	return ret
+}
+
+// Open decrypts and authenticates the ciphertext, authenticates the additional data and, if successful,
+// appends the resulting plaintext to dst.
+func (c *ccm) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != c.nonceSize {
+		panic("crypto/cipher: incorrect nonce length given to CCM")
+	}
+	if len(ciphertext) < c.tagSize || uint64(len(ciphertext)-c.tagSize) > c.maxLength() {
+		return nil, ErrAuthenticationFailed
+	}
+
+	tag := ciphertext[len(ciphertext)-c.tagSize:]
+	ciphertext = ciphertext[:len(ciphertext)-c.tagSize]
+
+	ret, out := sliceForAppend(dst, len(ciphertext))
+	c.crypt(nonce, out, ciphertext)
+
+	if subtle.ConstantTimeCompare(c.tag(nonce, out, additionalData), tag) != 1 {
+		for i := range out {
+			out[i] = 0
+		}
+		return nil, ErrAuthenticationFailed
+	}
+
+	return ret, nil
+}
+
+// counter returns the counter block with the nonce and counter value.
+func (c *ccm) counter(nonce []byte, value uint64) []byte {
+	block := make([]byte, ccmBlockSize)
+	block[0] = byte(14 - c.nonceSize)
+	copy(block[1:], nonce)
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], value)
+	copy(block[1+c.nonceSize:], counter[8-(15-c.nonceSize):])
+
+	return block
+}
+
+// crypt encrypts or decrypts src in counter mode starting from the counter 1.
+func (c *ccm) crypt(nonce, dst, src []byte) {
+	cipher.NewCTR(c.block, c.counter(nonce, 1)).XORKeyStream(dst, src)
+}
+
+// tag returns the CBC-MAC of the nonce, additional data and plaintext encrypted by the counter 0.
+func (c *ccm) tag(nonce, plaintext, additionalData []byte) []byte {
+	q := 15 - c.nonceSize
+
+	b0 := make([]byte, ccmBlockSize)
+	b0[0] = byte((c.tagSize-2)/2<<3 | (q - 1))
+	if len(additionalData) > 0 {
+		b0[0] |= 1 << 6
+	}
+	copy(b0[1:], nonce)
+
+	var length [8]byte
+	binary.BigEndian.PutUint64(length[:], uint64(len(plaintext)))
+	copy(b0[1+c.nonceSize:], length[8-q:])
+
+	mac := make([]byte, ccmBlockSize)
+	c.block.Encrypt(mac, b0)
+
+	if len(additionalData) > 0 {
+		var encoded []byte
+		switch n := uint64(len(additionalData)); {
+		case n < 1<<16-1<<8:
+			encoded = binary.BigEndian.AppendUint16(nil, uint16(n))
+		case n <= math.MaxUint32:
+			encoded = binary.BigEndian.AppendUint32([]byte{0xff, 0xfe}, uint32(n))
+		default:
+			encoded = binary.BigEndian.AppendUint64([]byte{0xff, 0xff}, n)
+		}
+		c.cbcMAC(mac, append(encoded, additionalData...))
+	}
+
+	c.cbcMAC(mac, plaintext)
+
+	s0 := make([]byte, ccmBlockSize)
+	c.block.Encrypt(s0, c.counter(nonce, 0))
+	subtle.XORBytes(mac, mac, s0)
+
+	return mac[:c.tagSize]
+}
+
+// cbcMAC updates the CBC-MAC with the data which is padded with zero bytes to the block size.
+func (c *ccm) cbcMAC(mac, data []byte) {
+	for len(data) > 0 {
+		n := subtle.XORBytes(mac, mac, data)
+		c.block.Encrypt(mac, mac)
+		data = data[n:]
+	}
+}
+
+// sliceForAppend extends the input slice by n bytes. head is the full extended slice, while tail is the appended part.
+func sliceForAppend(in []byte, n int) (head, tail []byte) {
+	if total := len(in) + n; cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
+	}
+	tail = head[len(in):]
+	return
+}
@@ -0,0 +1,176 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/suyuan32/knife/cryptox/symmetric/method"
+	"github.com/suyuan32/knife/cryptox/symmetric/mode"
+)
+
+func TestCryptoS_CCM_KnownAnswer(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  method.MethodType
+		key     string
+		nonce   string
+		aad     string
+		input   string
+		tagSize int
+		want    string
+	}{
+		{
+			name:    "SP 800-38C example 1",
+			method:  method.AES,
+			key:     "404142434445464748494a4b4c4d4e4f",
+			nonce:   "10111213141516",
+			aad:     "0001020304050607",
+			input:   "20212223",
+			tagSize: 4,
+			want:    "7162015b4dac255d",
+		},
+		{
+			name:    "SP 800-38C example 2",
+			method:  method.AES,
+			key:     "404142434445464748494a4b4c4d4e4f",
+			nonce:   "1011121314151617",
+			aad:     "000102030405060708090a0b0c0d0e0f",
+			input:   "202122232425262728292a2b2c2d2e2f",
+			tagSize: 6,
+			want:    "d2a1f0e051ea5f62081a7792073d593d1fc64fbfaccd",
+		},
+		{
+			name:    "SP 800-38C example 3",
+			method:  method.AES,
+			key:     "404142434445464748494a4b4c4d4e4f",
+			nonce:   "101112131415161718191a1b",
+			aad:     "000102030405060708090a0b0c0d0e0f10111213",
+			input:   "202122232425262728292a2b2c2d2e2f3031323334353637",
+			tagSize: 8,
+			want:    "e3b201a9f5b71a7a9b1ceaeccd97e70b6176aad9a4428aa5484392fbc1b09951",
+		},
+		{
+			name:   "SM4-CCM RFC 8998",
+			method: method.SM4,
+			key:    "0123456789abcdeffedcba9876543210",
+			nonce:  "00001234567800000000abcd",
+			aad:    "feedfacedeadbeeffeedfacedeadbeefabaddad2",
+			input: "aaaaaaaaaaaaaaaabbbbbbbbbbbbbbbbccccccccccccccccdddddddddddddddd" +
+				"eeeeeeeeeeeeeeeeffffffffffffffffeeeeeeeeeeeeeeeeaaaaaaaaaaaaaaaa",
+			tagSize: 16,
+			want: "48af93501fa62adbcd414cce6034d895dda1bf8f132f042098661572e7483094" +
+				"fd12e518ce062c98acee28d95df4416bed31a2f04476c18bb40c84a74b97dc5b" +
+				"16842d4fa186f56ab33256971fa110f4",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aad, _ := hex.DecodeString(tt.aad)
+			c := NewCryptoS()
+			result, err := c.InputFromHexString(tt.input).
+				WithMethod(tt.method).
+				WithMode(mode.CCM).
+				WithNonceSize(len(tt.nonce) / 2).
+				WithTagSize(tt.tagSize).
+				IVFromHexString(tt.nonce).
+				KeyFromHexString(tt.key).
+				WithAdditionalData(aad).
+				Encrypt().
+				ToHexString()
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, result)
+
+			c = NewCryptoS()
+			result, err = c.InputFromHexString(tt.want).
+				WithMethod(tt.method).
+				WithMode(mode.CCM).
+				WithNonceSize(len(tt.nonce) / 2).
+				WithTagSize(tt.tagSize).
+				IVFromHexString(tt.nonce).
+				KeyFromHexString(tt.key).
+				WithAdditionalData(aad).
+				Decrypt().
+				ToHexString()
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.input, result)
+		})
+	}
+}
+
+func TestCryptoS_CCM(t *testing.T) {
+	key := bytes.Repeat([]byte{'k'}, 16)
+	for _, nonceSize := range []int{7, 10, 13} {
+		for _, tagSize := range []int{4, 10, 16} {
+			for _, size := range []int{0, 1, 16, 100} {
+				for _, aad := range [][]byte{nil, []byte("header"), bytes.Repeat([]byte{'a'}, 0x10000)} {
+					plaintext := bytes.Repeat([]byte{'p'}, size)
+
+					c := NewCryptoS()
+					c.WithMode(mode.CCM).WithNonceSize(nonceSize).WithTagSize(tagSize).WithRandomIV().
+						WithKey(key).WithAdditionalData(aad)
+					built, err := c.Build()
+					assert.Nil(t, err)
+
+					ciphertext, err := built.Encrypt(plaintext)
+					if size == 0 {
+						assert.NotNil(t, err)
+						continue
+					}
+					assert.Nil(t, err)
+					assert.Equal(t, nonceSize+size+tagSize, len(ciphertext))
+
+					result, err := built.Decrypt(ciphertext)
+					assert.Nil(t, err)
+					assert.Equal(t, plaintext, result)
+
+					ciphertext[len(ciphertext)-1] ^= 1
+					_, err = built.Decrypt(ciphertext)
+					assert.True(t, errors.Is(err, ErrAuthenticationFailed))
+				}
+			}
+		}
+	}
+
+	// invalid nonce and tag size
+	c := NewCryptoS()
+	_, err := c.WithMode(mode.CCM).WithNonceSize(6).WithRandomIV().WithKey(key).Build()
+	assert.NotNil(t, err)
+
+	c = NewCryptoS()
+	_, err = c.WithMode(mode.CCM).WithTagSize(5).WithRandomIV().WithKey(key).Build()
+	assert.NotNil(t, err)
+
+	// envelope records the tag size
+	c = NewCryptoS()
+	c.WithMode(mode.CCM).WithNonceSize(13).WithTagSize(8).WithRandomIV().WithKey(key).
+		InputFromString("hello").Encrypt()
+	assert.Nil(t, c.Errors)
+
+	envelope, err := c.ToEnvelope()
+	assert.Nil(t, err)
+
+	c = NewCryptoS()
+	result, err := c.WithKey(key).FromEnvelope(envelope).Decrypt().ToString()
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", result)
+}
@@ -17,8 +17,11 @@ package symmetric
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/des"
 	"errors"
+	"fmt"
 
+	"golang.org/x/crypto/blowfish"
 	"golang.org/x/crypto/cast5"
 	"golang.org/x/crypto/tea"
 	"golang.org/x/crypto/twofish"
@@ -28,21 +31,133 @@ import (
 	"github.com/suyuan32/knife/cryptox/symmetric/method/sm4"
 )
 
+// operation is the state shared by the steps of an operation such as Encrypt. The key of KeyHandle is borrowed
+// for the operation, and the cipher block of the registered method is created once and reused by the validation
+// and the encryption.
+type operation struct {
+	key          []byte
+	block        cipher.Block
+	blockErr     error
+	blockCreated bool
+}
+
+// operate runs fn as an operation, the nested calls share the state of the outermost operation.
+// If KeyHandle is set, fn runs inside its Use, so the key cannot be destroyed until the operation ends.
+func (s *CryptoS) operate(fn func()) {
+	if s.op != nil {
+		fn()
+		return
+	}
+
+	s.op = &operation{}
+	defer func() { s.op = nil }()
+
+	// the destroyed handle is reported by validateKey
+	if s.KeyHandle != nil {
+		err := s.KeyHandle.Use(func(key []byte) error {
+			s.op.key = key
+			fn()
+			return nil
+		})
+		if err == nil {
+			return
+		}
+	}
+
+	fn()
+}
+
 // NewCipher returns a cipher block from the cryptos.
-func (s *CryptoS) NewCipher() (cipher.Block, error) {
+func (s *CryptoS) NewCipher() (block cipher.Block, err error) {
+	s.operate(func() { block, err = s.newCipher() })
+	return block, err
+}
+
+// newCipher returns a cipher block from the cryptos in an operation.
+func (s *CryptoS) newCipher() (cipher.Block, error) {
+	if factory, ok := method.Lookup(s.Method); ok {
+		return s.registeredBlock(factory)
+	}
+	return s.newBlock(s.key())
+}
+
+// registeredBlock returns the cipher block of the registered method with the key of CryptoS, the block is created
+// once in an operation.
+func (s *CryptoS) registeredBlock(factory method.Factory) (cipher.Block, error) {
+	if s.op == nil {
+		return factory(s.key())
+	}
+
+	if !s.op.blockCreated {
+		s.op.block, s.op.blockErr = factory(s.key())
+		s.op.blockCreated = true
+	}
+	return s.op.block, s.op.blockErr
+}
+
+// blockSize returns the block size of the method, it returns 0 if the method is not a block cipher.
+func (s *CryptoS) blockSize() int {
 	switch s.Method {
 	case method.AES:
-		return aes.NewCipher(s.Key)
+		return aes.BlockSize
 	case method.SM4:
-		return sm4.NewCipher(s.Key)
+		return sm4.BlockSize
 	case method.CAST5:
-		return cast5.NewCipher(s.Key)
+		return cast5.BlockSize
 	case method.Twofish:
-		return twofish.NewCipher(s.Key)
+		return twofish.BlockSize
 	case method.TEA:
-		return tea.NewCipher(s.Key)
+		return tea.BlockSize
 	case method.XTEA:
-		return xtea.NewCipher(s.Key)
+		return xtea.BlockSize
+	case method.DES, method.TripleDES:
+		return des.BlockSize
+	case method.Blowfish:
+		return blowfish.BlockSize
+	}
+
+	// the block size of registered methods is only known after the block is created
+	if factory, ok := method.Lookup(s.Method); ok {
+		if block, err := s.registeredBlock(factory); err == nil {
+			return block.BlockSize()
+		}
+	}
+	return 0
+}
+
+// newBlock returns a cipher block of the method with the key.
+func (s *CryptoS) newBlock(key []byte) (cipher.Block, error) {
+	if s.Method.IsInsecure() && !s.InsecureAllowed {
+		return nil, fmt.Errorf("the %s method is insecure, call AllowInsecure to use it", s.Method)
+	}
+
+	switch s.Method {
+	case method.AES:
+		return aes.NewCipher(key)
+	case method.SM4:
+		return sm4.NewCipher(key)
+	case method.CAST5:
+		return cast5.NewCipher(key)
+	case method.Twofish:
+		return twofish.NewCipher(key)
+	case method.TEA:
+		return tea.NewCipher(key)
+	case method.XTEA:
+		return xtea.NewCipher(key)
+	case method.DES:
+		return des.NewCipher(key)
+	case method.TripleDES:
+		// the two-key variant uses K1, K2, K1
+		if len(key) == 16 {
+			key = append(key[:16:16], key[:8]...)
+		}
+		return des.NewTripleDESCipher(key)
+	case method.Blowfish:
+		return blowfish.NewCipher(key)
+	}
+
+	if factory, ok := method.Lookup(s.Method); ok {
+		return factory(key)
 	}
 	return nil, errors.New("the method is not supported")
 }
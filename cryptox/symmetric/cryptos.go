@@ -15,6 +15,9 @@
 package symmetric
 
 import (
+	"github.com/suyuan32/knife/cryptox/keyhandle"
+	"github.com/suyuan32/knife/cryptox/symmetric/kdf"
+	"github.com/suyuan32/knife/cryptox/symmetric/mac"
 	"github.com/suyuan32/knife/cryptox/symmetric/method"
 	"github.com/suyuan32/knife/cryptox/symmetric/mode"
 	"github.com/suyuan32/knife/cryptox/symmetric/padding"
@@ -31,6 +34,10 @@ type CryptoS struct {
 	// Key is the secret used for encrypted
 	Key []byte
 
+	// KeyHandle is the opaque handle of the key, it takes precedence over Key and cannot be used with Keyring.
+	// The handle is not destroyed by CryptoS, it should be destroyed by the owner after use.
+	KeyHandle *keyhandle.Handle
+
 	// An initialization vector (IV) is an arbitrary number that can be used with a secret key for data encryption
 	// to foil cyberattacks. This number, also called a nonce (number used once), is employed only one time in
 	// any session to prevent unauthorized decryption of the message by a suspicious or malicious actor.
@@ -42,11 +49,45 @@ type CryptoS struct {
 	// Mode is the encrypted mode such as ECB.
 	Mode mode.ModeType
 
-	// Padding is the padding method such as PKCS7.
+	// Padding is the padding method such as PKCS7, zero means no padding.
 	Padding padding.PaddingType
 
+	// AdditionalData is the associated data which is authenticated but not encrypted by AEAD modes such as GCM.
+	AdditionalData []byte
+
+	// NonceSize is the nonce size used by AEAD modes such as GCM. The standard nonce size is used if it is zero.
+	NonceSize int
+
+	// TagSize is the authentication tag size used by the CCM mode. The 16 bytes tag is used if it is zero.
+	TagSize int
+
+	// MAC is the message authentication code used by encrypt-then-MAC such as HMAC-SHA256, zero means no MAC.
+	MAC mac.MACType
+
+	// RandomIV is true if a random IV is generated for each encryption and prepended to the output data,
+	// the IV is read back from the front of the input data when decrypting.
+	RandomIV bool
+
+	// KeyID is the identifier of the key, it is recorded in the envelope to find the key for decryption.
+	KeyID string
+
+	// KDF is the key derivation parameters recorded when the key is derived from a password.
+	KDF *kdf.Params
+
+	// Keyring holds the keys selected by KeyID, the primary key is used for encryption and Key is overwritten.
+	Keyring *Keyring
+
+	// ECBAllowed is true if the insecure ECB mode is allowed to be used.
+	ECBAllowed bool
+
+	// InsecureAllowed is true if the insecure methods such as DES are allowed to be used.
+	InsecureAllowed bool
+
 	// Errors is the errors
 	Errors error
+
+	// op is the state of the running operation, it is nil between operations.
+	op *operation
 }
 
 func NewCryptoS() CryptoS {
@@ -78,6 +119,14 @@ func (s *CryptoS) WithPadding(padding padding.PaddingType) *CryptoS {
 	return s
 }
 
+// paddingType returns the padding method, padding.No is returned if Padding is not set.
+func (s *CryptoS) paddingType() padding.PaddingType {
+	if s.Padding == 0 {
+		return padding.No
+	}
+	return s.Padding
+}
+
 // WithIV set IV for CryptoS.
 func (s *CryptoS) WithIV(data []byte) *CryptoS {
 	s.IV = data
@@ -90,12 +139,72 @@ func (s *CryptoS) WithKey(data []byte) *CryptoS {
 	return s
 }
 
+// WithKeyHandle set the opaque key handle, the key is read from the handle only when it is used.
+func (s *CryptoS) WithKeyHandle(handle *keyhandle.Handle) *CryptoS {
+	s.KeyHandle = handle
+	return s
+}
+
+// WithMAC set encrypt-then-MAC for CryptoS. Separate encryption and MAC keys are derived from the key,
+// the tag of the associated data, IV and ciphertext is appended to the output data, and it is verified
+// before decrypting. It is used to add integrity to modes such as CBC and CTR.
+func (s *CryptoS) WithMAC(macType mac.MACType) *CryptoS {
+	s.MAC = macType
+	return s
+}
+
+// WithRandomIV makes CryptoS generate a random IV by crypto/rand for each encryption and prepend it to
+// the output data, the IV is read back from the front of the input data when decrypting.
+func (s *CryptoS) WithRandomIV() *CryptoS {
+	s.RandomIV = true
+	return s
+}
+
+// WithKeyID set key identifier for CryptoS.
+func (s *CryptoS) WithKeyID(id string) *CryptoS {
+	s.KeyID = id
+	return s
+}
+
+// WithAdditionalData set associated data for AEAD modes such as GCM.
+func (s *CryptoS) WithAdditionalData(data []byte) *CryptoS {
+	s.AdditionalData = data
+	return s
+}
+
+// WithNonceSize set nonce size for AEAD modes such as GCM.
+func (s *CryptoS) WithNonceSize(size int) *CryptoS {
+	s.NonceSize = size
+	return s
+}
+
+// WithTagSize set the authentication tag size for the CCM mode.
+func (s *CryptoS) WithTagSize(size int) *CryptoS {
+	s.TagSize = size
+	return s
+}
+
+// WithKeyring set the keyring, the primary key and its ID are used for encryption,
+// and the key is found by KeyID for decryption.
+func (s *CryptoS) WithKeyring(keyring *Keyring) *CryptoS {
+	s.Keyring = keyring
+	return s
+}
+
+// AllowECB allows CryptoS to use the insecure ECB mode. ECB should only be used for legacy interfaces.
+func (s *CryptoS) AllowECB() *CryptoS {
+	s.ECBAllowed = true
+	return s
+}
+
+// AllowInsecure allows CryptoS to use the insecure methods DES, TripleDES and Blowfish. They should only be used
+// to decrypt payloads of legacy systems.
+func (s *CryptoS) AllowInsecure() *CryptoS {
+	s.InsecureAllowed = true
+	return s
+}
+
 // Reset set all data to default for CryptoS.
 func (s *CryptoS) Reset() {
-	s.InputData = nil
-	s.Errors = nil
-	s.Key = nil
-	s.IV = nil
-	s.Method = method.AES
-	s.Mode = mode.CBC
+	*s = NewCryptoS()
 }
@@ -16,10 +16,19 @@ package symmetric
 
 import (
 	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"errors"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/suyuan32/knife/core/codec"
+	"github.com/suyuan32/knife/cryptox/keyhandle"
+	"github.com/suyuan32/knife/cryptox/symmetric/kdf"
 	"github.com/suyuan32/knife/cryptox/symmetric/method"
 	"github.com/suyuan32/knife/cryptox/symmetric/mode"
 	"github.com/suyuan32/knife/cryptox/symmetric/padding"
@@ -130,6 +139,16 @@ func TestCryptoS_Input(t *testing.T) {
 
 	data.InputFromHexString("68656C6C6F")
 	assert.Equal(t, "hello", string(data.InputData))
+
+	data.InputFromEncodedString("aGVsbG8", codec.Base64RawURL)
+	assert.Equal(t, "hello", string(data.InputData))
+
+	data.InputFromEncodedString("Cn8eVZg", codec.Base58)
+	assert.Equal(t, "hello", string(data.InputData))
+	assert.Nil(t, data.Errors)
+
+	data.InputFromEncodedString("aGVsbG8=", codec.Base64RawURL)
+	assert.NotNil(t, data.Errors)
 }
 
 func TestCryptoS_Key(t *testing.T) {
@@ -145,6 +164,52 @@ func TestCryptoS_Key(t *testing.T) {
 
 	data.KeyFromHexString("68656C6C6F")
 	assert.Equal(t, "hello", string(data.Key))
+
+	data.KeyFromEncodedString("NBSWY3DP", codec.Base32Std)
+	assert.Equal(t, "hello", string(data.Key))
+	assert.Nil(t, data.Errors)
+}
+
+func TestCryptoS_KeyFromPassword(t *testing.T) {
+	testStr := bytes.Repeat([]byte{'a'}, 120)
+	for _, v := range []method.MethodType{method.AES, method.SM4, method.ChaCha20Poly1305} {
+		for _, params := range []kdf.Params{kdf.NewPBKDF2(1000), kdf.NewScrypt(1024, 8, 1), kdf.NewArgon2id(1, 64, 1)} {
+			c := NewCryptoS()
+			c.WithMethod(v).
+				WithPadding(padding.PKCS7).
+				WithIV(bytes.Repeat([]byte{'b'}, 16)).
+				KeyFromPassword("password", nil, params)
+			if v == method.ChaCha20Poly1305 {
+				c.WithIV(bytes.Repeat([]byte{'b'}, 12))
+			}
+
+			assert.Nil(t, c.Errors)
+			assert.Equal(t, c.keySize(), len(c.Key))
+			assert.Equal(t, kdf.DefaultSaltSize, len(c.KDF.Salt))
+
+			envelope, err := c.InputFromBytes(testStr).Encrypt().ToEnvelope()
+			assert.Nil(t, err)
+
+			// the key is derived with the parameters from the envelope
+			c = NewCryptoS()
+			result, err := c.FromEnvelope(envelope).DeriveKey("password").Decrypt().ToBytes()
+			assert.Nil(t, err)
+			assert.Equal(t, testStr, result)
+		}
+	}
+
+	data.Reset()
+	data.KeyFromPassword("password", []byte("salt"), kdf.NewPBKDF2(1))
+	assert.Nil(t, data.Errors)
+	assert.Equal(t, "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b", hex.EncodeToString(data.Key))
+
+	data.Reset()
+	data.KeyFromPassword("password", []byte("salt"), kdf.NewPBKDF2(0))
+	assert.NotNil(t, data.Errors)
+
+	data.Reset()
+	data.DeriveKey("password")
+	assert.NotNil(t, data.Errors)
 }
 
 func TestCryptoS_IV(t *testing.T) {
@@ -160,6 +225,81 @@ func TestCryptoS_IV(t *testing.T) {
 
 	data.IVFromHexString("68656C6C6F")
 	assert.Equal(t, "hello", string(data.IV))
+
+	data.IVFromEncodedString("aGVsbG8", codec.Base64RawStd)
+	assert.Equal(t, "hello", string(data.IV))
+	assert.Nil(t, data.Errors)
+}
+
+func TestCryptoS_RandomIV(t *testing.T) {
+	testStr := bytes.Repeat([]byte{'a'}, 120)
+	tests := []struct {
+		method method.MethodType
+		mode   mode.ModeType
+		ivSize int
+	}{
+		{method: method.AES, mode: mode.CBC, ivSize: 16},
+		{method: method.SM4, mode: mode.CTR, ivSize: 16},
+		{method: method.XTEA, mode: mode.CFB, ivSize: 8},
+		{method: method.AES, mode: mode.GCM, ivSize: 12},
+		{method: method.XChaCha20Poly1305, ivSize: 24},
+		{method: method.AES, mode: mode.ECB, ivSize: 0},
+	}
+
+	for _, tt := range tests {
+		key := bytes.Repeat([]byte{'c'}, 16)
+		if tt.method == method.XChaCha20Poly1305 {
+			key = bytes.Repeat([]byte{'c'}, 32)
+		}
+
+		var results [][]byte
+		for i := 0; i < 2; i++ {
+			c := NewCryptoS()
+			result, err := c.InputFromBytes(testStr).
+				WithMethod(tt.method).
+				WithMode(tt.mode).
+				WithPadding(padding.PKCS7).
+				WithKey(key).
+				WithRandomIV().
+				AllowECB().
+				Encrypt().
+				ToBytes()
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.ivSize, len(c.IV))
+			assert.Equal(t, c.IV, result[:tt.ivSize])
+			results = append(results, result)
+
+			c = NewCryptoS()
+			decryptResult, err := c.InputFromBytes(result).
+				WithMethod(tt.method).
+				WithMode(tt.mode).
+				WithPadding(padding.PKCS7).
+				WithKey(key).
+				WithRandomIV().
+				AllowECB().
+				Decrypt().
+				ToBytes()
+
+			assert.Nil(t, err)
+			assert.Equal(t, testStr, decryptResult)
+		}
+
+		if tt.ivSize > 0 {
+			assert.NotEqual(t, results[0], results[1])
+		}
+	}
+
+	// the input data is shorter than IV
+	for _, m := range []mode.ModeType{mode.CBC, mode.GCM} {
+		c := NewCryptoS()
+		c.InputFromBytes([]byte{1, 2, 3}).
+			WithMode(m).
+			WithKey(bytes.Repeat([]byte{'c'}, 16)).
+			WithRandomIV().
+			Decrypt()
+		assert.NotNil(t, c.Errors)
+	}
 }
 
 func TestCryptoS_Output(t *testing.T) {
@@ -180,6 +320,14 @@ func TestCryptoS_Output(t *testing.T) {
 	result, err = data.ToHexString()
 	assert.Nil(t, err)
 	assert.Equal(t, "68656c6c6f", result)
+
+	result, err = data.ToEncodedString(codec.Base64RawURL)
+	assert.Nil(t, err)
+	assert.Equal(t, "aGVsbG8", result)
+
+	result, err = data.ToEncodedString(codec.Base58)
+	assert.Nil(t, err)
+	assert.Equal(t, "Cn8eVZg", result)
 }
 
 func TestCryptoS_Validate(t *testing.T) {
@@ -195,3 +343,206 @@ func TestCryptoS_Validate(t *testing.T) {
 	err = data.Validate(3)
 	assert.NotNil(t, err)
 }
+
+func TestCryptoS_PKCS5(t *testing.T) {
+	// AES/CBC/PKCS5Padding of Java pads to the 16 bytes block of AES
+	c := NewCryptoS()
+	result, err := c.InputFromString("hello java").
+		WithPadding(padding.PKCS5).
+		KeyFromString("1234567890123456").
+		IVFromString("6543210987654321").
+		Encrypt().
+		ToHexString()
+	assert.Nil(t, err)
+	assert.Equal(t, "442da4633ba3c28f58bdf0d138e09487", result)
+
+	for _, p := range []padding.PaddingType{padding.PKCS5, padding.ANSIX923, padding.ISO10126} {
+		c = NewCryptoS()
+		encrypted, err := c.InputFromString("hello padding").WithPadding(p).
+			KeyFromString("1234567890123456").IVFromString("6543210987654321").Encrypt().ToBytes()
+		assert.Nil(t, err)
+
+		c = NewCryptoS()
+		decrypted, err := c.InputFromBytes(encrypted).WithPadding(p).
+			KeyFromString("1234567890123456").IVFromString("6543210987654321").Decrypt().ToString()
+		assert.Nil(t, err)
+		assert.Equal(t, "hello padding", decrypted)
+	}
+}
+
+func TestCryptoS_Decrypt_InvalidPadding(t *testing.T) {
+	c := NewCryptoS()
+	c.WithPadding(padding.PKCS7).KeyFromString("1234567890123456").IVFromString("1234567890123456").
+		InputFromString("hello world").Encrypt()
+	assert.Nil(t, c.Errors)
+	ciphertext := c.OutputData
+
+	for _, p := range []padding.PaddingType{padding.PKCS7, padding.PKCS5, padding.ISO97971, padding.Zero} {
+		d := NewCryptoS()
+		d.WithPadding(p).KeyFromString("6543210987654321").IVFromString("1234567890123456").
+			InputFromBytes(ciphertext).Decrypt()
+		assert.True(t, errors.Is(d.Errors, padding.ErrInvalidPadding))
+		assert.Nil(t, d.OutputData)
+	}
+}
+
+func TestCryptoS_ECB(t *testing.T) {
+	testStr := bytes.Repeat([]byte{'a'}, 120)
+	for _, v := range []method.MethodType{method.AES, method.Twofish, method.SM4, method.CAST5, method.TEA, method.XTEA} {
+		for _, p := range []padding.PaddingType{padding.Zero, padding.ISO97971, padding.PKCS7} {
+			c := NewCryptoS()
+			result, err := c.InputFromBytes(testStr).
+				WithMethod(v).
+				WithMode(mode.ECB).
+				WithPadding(p).
+				WithKey(bytes.Repeat([]byte{'c'}, 16)).
+				AllowECB().
+				Encrypt().
+				ToBytes()
+
+			assert.Nil(t, err)
+
+			c = NewCryptoS()
+			decryptResult, err := c.InputFromBytes(result).
+				WithMethod(v).
+				WithMode(mode.ECB).
+				WithPadding(p).
+				WithKey(bytes.Repeat([]byte{'c'}, 16)).
+				AllowECB().
+				Decrypt().
+				ToBytes()
+
+			assert.Nil(t, err)
+			assert.Equal(t, testStr, decryptResult)
+		}
+	}
+
+	// known answer
+	c := NewCryptoS()
+	result, err := c.InputFromHexString("6bc1bee22e409f96e93d7e117393172a").
+		WithMode(mode.ECB).
+		WithPadding(padding.No).
+		KeyFromHexString("2b7e151628aed2a6abf7158809cf4f3c").
+		AllowECB().
+		Encrypt().
+		ToHexString()
+	assert.Nil(t, err)
+	assert.Equal(t, "3ad77bb40d7a3660a89ecaf32466ef97", result)
+
+	c = NewCryptoS()
+	result, err = c.InputFromHexString("0123456789abcdeffedcba9876543210").
+		WithMethod(method.SM4).
+		WithMode(mode.ECB).
+		WithPadding(padding.No).
+		KeyFromHexString("0123456789abcdeffedcba9876543210").
+		AllowECB().
+		Encrypt().
+		ToHexString()
+	assert.Nil(t, err)
+	assert.Equal(t, "681edf34d206965e86b3e94f536e4246", result)
+
+	// ECB must be allowed explicitly
+	c = NewCryptoS()
+	c.InputFromBytes(testStr).
+		WithMode(mode.ECB).
+		WithPadding(padding.PKCS7).
+		WithKey(bytes.Repeat([]byte{'c'}, 16)).
+		Encrypt()
+	assert.NotNil(t, c.Errors)
+
+	// the data without padding must be full blocks
+	c = NewCryptoS()
+	c.InputFromString("hello").
+		WithMode(mode.ECB).
+		WithPadding(padding.No).
+		WithKey(bytes.Repeat([]byte{'c'}, 16)).
+		AllowECB().
+		Encrypt()
+	assert.NotNil(t, c.Errors)
+}
+
+func TestCryptoS_WithKeyHandle(t *testing.T) {
+	testStr := []byte("key handle data")
+	key := bytes.Repeat([]byte{'c'}, 16)
+	iv := bytes.Repeat([]byte{'b'}, 16)
+
+	c := NewCryptoS()
+	expected, err := c.InputFromBytes(testStr).WithIV(iv).WithKey(key).WithPadding(padding.PKCS7).
+		Encrypt().ToBytes()
+	assert.Nil(t, err)
+
+	handle, err := keyhandle.Seal(bytes.Clone(key))
+	assert.Nil(t, err)
+
+	c = NewCryptoS()
+	result, err := c.InputFromBytes(testStr).WithIV(iv).WithKeyHandle(handle).WithPadding(padding.PKCS7).
+		Encrypt().ToBytes()
+	assert.Nil(t, err)
+	assert.Equal(t, expected, result)
+
+	c = NewCryptoS()
+	result, err = c.InputFromBytes(expected).WithIV(iv).WithKeyHandle(handle).WithPadding(padding.PKCS7).
+		Decrypt().ToBytes()
+	assert.Nil(t, err)
+	assert.Equal(t, testStr, result)
+
+	// the handle takes precedence over the key
+	c = NewCryptoS()
+	cipher, err := c.WithIV(iv).WithKey(bytes.Repeat([]byte{'d'}, 16)).WithKeyHandle(handle).
+		WithPadding(padding.PKCS7).Build()
+	assert.Nil(t, err)
+	result, err = cipher.Encrypt(testStr)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, result)
+
+	// the destroyed handle cannot be used
+	handle.Destroy()
+	c = NewCryptoS()
+	c.InputFromBytes(testStr).WithIV(iv).WithKeyHandle(handle).WithPadding(padding.PKCS7).Encrypt()
+	var validationErr *ValidationError
+	assert.True(t, errors.As(c.Errors, &validationErr))
+	assert.Equal(t, "key", validationErr.Field)
+	assert.Equal(t, keyhandle.ErrDestroyed.Error(), validationErr.Constraint)
+
+	_, err = cipher.Encrypt(testStr)
+	assert.NotNil(t, err)
+}
+
+func TestCryptoS_WithKeyHandle_Destroy(t *testing.T) {
+	testStr := []byte("key handle data")
+	key := bytes.Repeat([]byte{'c'}, 16)
+	iv := bytes.Repeat([]byte{'b'}, 16)
+
+	c := NewCryptoS()
+	expected, err := c.InputFromBytes(testStr).WithIV(iv).WithKey(key).WithMode(mode.CTR).Encrypt().ToBytes()
+	assert.Nil(t, err)
+
+	handle, err := keyhandle.Seal(bytes.Clone(key))
+	assert.Nil(t, err)
+
+	// the handle is destroyed while the cipher is being created
+	var once sync.Once
+	destroyed := make(chan struct{})
+	m := method.FirstRegistered + 76
+	err = method.Register(m, "TestSlowAES", func(key []byte) (cipher.Block, error) {
+		once.Do(func() {
+			go func() {
+				handle.Destroy()
+				close(destroyed)
+			}()
+		})
+		time.Sleep(10 * time.Millisecond)
+		return aes.NewCipher(key)
+	})
+	assert.Nil(t, err)
+
+	// Destroy waits until the operation ends
+	c = NewCryptoS()
+	result, err := c.InputFromBytes(testStr).WithIV(iv).WithKeyHandle(handle).WithMethod(m).WithMode(mode.CTR).
+		Encrypt().ToBytes()
+	assert.Nil(t, err)
+	assert.Equal(t, expected, result)
+
+	<-destroyed
+	assert.True(t, handle.Destroyed())
+}
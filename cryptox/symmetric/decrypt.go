@@ -29,19 +29,51 @@ func (s *CryptoS) Decrypt() *CryptoS {
 		return s
 	}
 
-	block, err := s.NewCipher()
+	if s.Keyring != nil {
+		if err := s.keyFromKeyring(false); err != nil {
+			s.Errors = errors.Join(s.Errors, fmt.Errorf("failed to get key from the keyring, error:%w", err))
+			return s
+		}
+	}
+
+	s.operate(func() { s.decrypt() })
+
+	return s
+}
+
+// decrypt decrypts the input data in the operation started by Decrypt.
+func (s *CryptoS) decrypt() *CryptoS {
+	if s.isAEAD() {
+		return s.openAEAD()
+	}
+
+	if s.RandomIV {
+		if err := s.splitIV(s.ivSize(s.blockSize())); err != nil {
+			s.Errors = errors.Join(s.Errors, err)
+			return s
+		}
+	}
+
+	err := s.Validate(s.blockSize())
 	if err != nil {
-		s.Errors = errors.Join(s.Errors, fmt.Errorf("failed to create cipher from the data, error:%s", err))
+		s.Errors = errors.Join(s.Errors, fmt.Errorf("failed to validate data, error:%w", err))
 		return s
 	}
 
-	err = s.Validate(block.BlockSize())
+	block, macKey, err := s.newBlockCipher()
 	if err != nil {
-		s.Errors = errors.Join(s.Errors, fmt.Errorf("failed to validate data, error:%s", err))
+		s.Errors = errors.Join(s.Errors, fmt.Errorf("failed to create cipher from the data, error:%s", err))
 		return s
 	}
 
-	if len(s.InputData)%block.BlockSize() != 0 {
+	if macKey != nil {
+		if err = s.verifyMAC(macKey); err != nil {
+			s.Errors = errors.Join(s.Errors, fmt.Errorf("failed to verify MAC, error:%w", err))
+			return s
+		}
+	}
+
+	if (s.Mode == mode.CBC || s.Mode == mode.ECB) && len(s.InputData)%block.BlockSize() != 0 {
 		s.Errors = errors.Join(s.Errors, errors.New("the data size needs to be an integer multiple of block size"))
 		return s
 	}
@@ -49,6 +81,8 @@ func (s *CryptoS) Decrypt() *CryptoS {
 	s.OutputData = make([]byte, len(s.InputData))
 
 	switch s.Mode {
+	case mode.ECB:
+		newECBDecrypter(block).CryptBlocks(s.OutputData, s.InputData)
 	case mode.CBC:
 		cipher.NewCBCDecrypter(block, s.IV).CryptBlocks(s.OutputData, s.InputData)
 	case mode.CFB:
@@ -59,9 +93,10 @@ func (s *CryptoS) Decrypt() *CryptoS {
 		cipher.NewCTR(block, s.IV).XORKeyStream(s.OutputData, s.InputData)
 	}
 
-	dePaddingData, err := padding.DePadding(s.OutputData, s.Padding, block.BlockSize())
+	dePaddingData, err := padding.DePadding(s.OutputData, s.paddingType(), block.BlockSize())
 	if err != nil {
-		s.Errors = errors.Join(s.Errors, fmt.Errorf("failed to depad data, error:%s", err))
+		s.OutputData = nil
+		s.Errors = errors.Join(s.Errors, fmt.Errorf("failed to depad data, error:%w", err))
 		return s
 	}
 
@@ -0,0 +1,59 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import "crypto/cipher"
+
+// ecb implements the ECB mode which encrypts or decrypts each block independently.
+type ecb struct {
+	block     cipher.Block
+	blockSize int
+	decrypt   bool
+}
+
+// newECBEncrypter returns a BlockMode which encrypts in electronic codebook mode.
+func newECBEncrypter(block cipher.Block) cipher.BlockMode {
+	return &ecb{block: block, blockSize: block.BlockSize()}
+}
+
+// newECBDecrypter returns a BlockMode which decrypts in electronic codebook mode.
+func newECBDecrypter(block cipher.Block) cipher.BlockMode {
+	return &ecb{block: block, blockSize: block.BlockSize(), decrypt: true}
+}
+
+// BlockSize returns the mode's block size.
+func (e *ecb) BlockSize() int {
+	return e.blockSize
+}
+
+// CryptBlocks encrypts or decrypts a number of blocks. The length of src must be a multiple of the block size.
+func (e *ecb) CryptBlocks(dst, src []byte) {
+	if len(src)%e.blockSize != 0 {
+		panic("crypto/cipher: input not full blocks")
+	}
+	if len(dst) < len(src) {
+		panic("crypto/cipher: output smaller than input")
+	}
+
+	for len(src) > 0 {
+		if e.decrypt {
+			e.block.Decrypt(dst[:e.blockSize], src[:e.blockSize])
+		} else {
+			e.block.Encrypt(dst[:e.blockSize], src[:e.blockSize])
+		}
+		src = src[e.blockSize:]
+		dst = dst[e.blockSize:]
+	}
+}
@@ -29,27 +29,59 @@ func (s *CryptoS) Encrypt() *CryptoS {
 		return s
 	}
 
-	block, err := s.NewCipher()
+	if s.Keyring != nil {
+		if err := s.keyFromKeyring(true); err != nil {
+			s.Errors = errors.Join(s.Errors, fmt.Errorf("failed to get key from the keyring, error:%w", err))
+			return s
+		}
+	}
+
+	s.operate(func() { s.encrypt() })
+
+	return s
+}
+
+// encrypt encrypts the input data in the operation started by Encrypt.
+func (s *CryptoS) encrypt() *CryptoS {
+	if s.isAEAD() {
+		return s.sealAEAD()
+	}
+
+	if s.RandomIV {
+		if err := s.generateIV(s.ivSize(s.blockSize())); err != nil {
+			s.Errors = errors.Join(s.Errors, err)
+			return s
+		}
+	}
+
+	err := s.Validate(s.blockSize())
 	if err != nil {
-		s.Errors = errors.Join(s.Errors, fmt.Errorf("failed to create cipher from the data, error:%s", err))
+		s.Errors = errors.Join(s.Errors, fmt.Errorf("failed to validate data, error:%w", err))
 		return s
 	}
 
-	err = s.Validate(block.BlockSize())
+	block, macKey, err := s.newBlockCipher()
 	if err != nil {
-		s.Errors = errors.Join(s.Errors, fmt.Errorf("failed to validate data, error:%s", err))
+		s.Errors = errors.Join(s.Errors, fmt.Errorf("failed to create cipher from the data, error:%s", err))
 		return s
 	}
 
-	paddingData, err := padding.Padding(s.InputData, s.Padding, block.BlockSize())
+	paddingData, err := padding.Padding(s.InputData, s.paddingType(), block.BlockSize())
 	if err != nil {
 		s.Errors = errors.Join(s.Errors, fmt.Errorf("failed to pad data, error:%s", err))
 		return s
 	}
 
+	if (s.Mode == mode.CBC || s.Mode == mode.ECB) && len(paddingData)%block.BlockSize() != 0 {
+		s.Errors = errors.Join(s.Errors, errors.New("the data size needs to be an integer multiple of block size"))
+		return s
+	}
+
 	s.OutputData = make([]byte, len(paddingData))
 
 	switch s.Mode {
+	case mode.ECB:
+		newECBEncrypter(block).CryptBlocks(s.OutputData, paddingData)
 	case mode.CBC:
 		cipher.NewCBCEncrypter(block, s.IV).CryptBlocks(s.OutputData, paddingData)
 	case mode.CFB:
@@ -63,5 +95,14 @@ func (s *CryptoS) Encrypt() *CryptoS {
 		return s
 	}
 
+	if macKey != nil {
+		if err = s.appendMAC(macKey); err != nil {
+			s.Errors = errors.Join(s.Errors, fmt.Errorf("failed to compute MAC, error:%s", err))
+			return s
+		}
+	}
+
+	s.prependIV()
+
 	return s
 }
@@ -0,0 +1,235 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/suyuan32/knife/core/codec"
+	"github.com/suyuan32/knife/cryptox/symmetric/kdf"
+	"github.com/suyuan32/knife/cryptox/symmetric/mac"
+	"github.com/suyuan32/knife/cryptox/symmetric/method"
+	"github.com/suyuan32/knife/cryptox/symmetric/mode"
+	"github.com/suyuan32/knife/cryptox/symmetric/padding"
+)
+
+// The envelope is a self-describing binary format of the ciphertext, its layout is:
+//
+//	magic "KNFE" | version (1 byte) | method (1 byte) | mode (1 byte) | padding (1 byte) |
+//	fields length (2 bytes) | fields | ciphertext
+//
+// Each field is encoded as tag (1 byte) | length (2 bytes) | value, unknown fields are skipped
+// so that new fields can be added without changing the version.
+
+// EnvelopeVersion is the current version of the envelope format.
+const EnvelopeVersion = 1
+
+// envelopeMagic is the magic number at the beginning of the envelope.
+var envelopeMagic = []byte("KNFE")
+
+// envelopeHeaderSize is the size of the fixed header before the fields.
+const envelopeHeaderSize = 10
+
+// The tags of envelope fields.
+const (
+	envelopeFieldIV byte = 1 + iota
+	envelopeFieldKeyID
+	envelopeFieldKDF
+	envelopeFieldMAC
+	envelopeFieldTagSize
+)
+
+// ErrInvalidEnvelope is returned when the envelope cannot be parsed.
+var ErrInvalidEnvelope = errors.New("invalid envelope")
+
+// ToEnvelope output data with the envelope format which records the method, mode, padding, IV, key ID,
+// key derivation parameters, MAC and tag size,
+// so the data can be decrypted by FromEnvelope without knowing the configuration.
+func (s *CryptoS) ToEnvelope() ([]byte, error) {
+	if s.Errors != nil {
+		return nil, s.Errors
+	}
+
+	var fields []byte
+	fields = appendEnvelopeField(fields, envelopeFieldIV, s.IV)
+	fields = appendEnvelopeField(fields, envelopeFieldKeyID, []byte(s.KeyID))
+	if s.KDF != nil {
+		fields = appendEnvelopeField(fields, envelopeFieldKDF, []byte(s.KDF.String()))
+	}
+	if s.MAC != 0 {
+		fields = appendEnvelopeField(fields, envelopeFieldMAC, []byte{byte(s.MAC)})
+	}
+	if s.TagSize != 0 {
+		fields = appendEnvelopeField(fields, envelopeFieldTagSize, []byte{byte(s.TagSize)})
+	}
+
+	if len(fields) > 0xffff {
+		return nil, fmt.Errorf("%w: the header is too large", ErrInvalidEnvelope)
+	}
+
+	// the random IV prepended to the output data is recorded in the header
+	ciphertext := s.OutputData
+	if s.RandomIV && len(ciphertext) >= len(s.IV) {
+		ciphertext = ciphertext[len(s.IV):]
+	}
+
+	result := make([]byte, 0, envelopeHeaderSize+len(fields)+len(ciphertext))
+	result = append(result, envelopeMagic...)
+	result = append(result, EnvelopeVersion, byte(s.Method), byte(s.Mode), byte(s.Padding))
+	result = binary.BigEndian.AppendUint16(result, uint16(len(fields)))
+	result = append(result, fields...)
+	result = append(result, ciphertext...)
+
+	return result, nil
+}
+
+// ToEnvelopeBase64String output data with the envelope format encoded by base64.
+func (s *CryptoS) ToEnvelopeBase64String() (string, error) {
+	return s.ToEnvelopeEncodedString(codec.Base64Std)
+}
+
+// ToEnvelopeHexString output data with the envelope format encoded by hex.
+func (s *CryptoS) ToEnvelopeHexString() (string, error) {
+	return s.ToEnvelopeEncodedString(codec.Hex)
+}
+
+// ToEnvelopeEncodedString output data with the envelope format encoded by the encoding such as codec.Base64RawURL.
+func (s *CryptoS) ToEnvelopeEncodedString(encoding codec.Encoding) (string, error) {
+	result, err := s.ToEnvelope()
+	if err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(result), nil
+}
+
+// FromEnvelope set method, mode, padding, IV, key ID, key derivation parameters, MAC, tag size and input data
+// from the envelope.
+// The key should be set according to the key ID, or derived by DeriveKey before decrypting.
+func (s *CryptoS) FromEnvelope(data []byte) *CryptoS {
+	if len(data) < envelopeHeaderSize || !bytes.Equal(data[:len(envelopeMagic)], envelopeMagic) {
+		s.Errors = errors.Join(s.Errors, ErrInvalidEnvelope)
+		return s
+	}
+
+	if version := data[4]; version != EnvelopeVersion {
+		s.Errors = errors.Join(s.Errors, fmt.Errorf("%w: unsupported version %d", ErrInvalidEnvelope, version))
+		return s
+	}
+
+	fieldsLen := int(binary.BigEndian.Uint16(data[8:envelopeHeaderSize]))
+	if len(data) < envelopeHeaderSize+fieldsLen {
+		s.Errors = errors.Join(s.Errors, ErrInvalidEnvelope)
+		return s
+	}
+
+	var iv, keyID []byte
+	var params *kdf.Params
+	var macType mac.MACType
+	var tagSize int
+	fields := data[envelopeHeaderSize : envelopeHeaderSize+fieldsLen]
+	for len(fields) > 0 {
+		if len(fields) < 3 {
+			s.Errors = errors.Join(s.Errors, ErrInvalidEnvelope)
+			return s
+		}
+
+		tag, size := fields[0], int(binary.BigEndian.Uint16(fields[1:3]))
+		if len(fields) < 3+size {
+			s.Errors = errors.Join(s.Errors, ErrInvalidEnvelope)
+			return s
+		}
+
+		value := fields[3 : 3+size]
+		switch tag {
+		case envelopeFieldIV:
+			iv = value
+		case envelopeFieldKeyID:
+			keyID = value
+		case envelopeFieldKDF:
+			result, err := kdf.Parse(string(value))
+			if err != nil {
+				s.Errors = errors.Join(s.Errors, fmt.Errorf("%w: %s", ErrInvalidEnvelope, err))
+				return s
+			}
+			params = &result
+		case envelopeFieldMAC:
+			if len(value) != 1 {
+				s.Errors = errors.Join(s.Errors, ErrInvalidEnvelope)
+				return s
+			}
+			macType = mac.MACType(value[0])
+		case envelopeFieldTagSize:
+			if len(value) != 1 {
+				s.Errors = errors.Join(s.Errors, ErrInvalidEnvelope)
+				return s
+			}
+			tagSize = int(value[0])
+		}
+
+		fields = fields[3+size:]
+	}
+
+	s.Method = method.MethodType(data[5])
+	s.Mode = mode.ModeType(data[6])
+	s.Padding = padding.PaddingType(data[7])
+	s.IV = iv
+	s.KeyID = string(keyID)
+	s.KDF = params
+	s.MAC = macType
+	s.TagSize = tagSize
+	s.RandomIV = false
+	s.InputData = data[envelopeHeaderSize+fieldsLen:]
+
+	if s.isAEAD() {
+		s.NonceSize = len(iv)
+	}
+
+	return s
+}
+
+// FromEnvelopeBase64String set configuration and input data from the envelope encoded by base64.
+func (s *CryptoS) FromEnvelopeBase64String(data string) *CryptoS {
+	return s.FromEnvelopeEncodedString(data, codec.Base64Std)
+}
+
+// FromEnvelopeHexString set configuration and input data from the envelope encoded by hex.
+func (s *CryptoS) FromEnvelopeHexString(data string) *CryptoS {
+	return s.FromEnvelopeEncodedString(data, codec.Hex)
+}
+
+// FromEnvelopeEncodedString set configuration and input data from the envelope encoded by the encoding
+// such as codec.Base64RawURL.
+func (s *CryptoS) FromEnvelopeEncodedString(data string, encoding codec.Encoding) *CryptoS {
+	result, err := encoding.DecodeString(data)
+	if err != nil {
+		s.Errors = errors.Join(s.Errors, err)
+		return s
+	}
+	return s.FromEnvelope(result)
+}
+
+// appendEnvelopeField appends a field to the envelope fields, empty values are omitted.
+func appendEnvelopeField(fields []byte, tag byte, value []byte) []byte {
+	if len(value) == 0 {
+		return fields
+	}
+
+	fields = append(fields, tag)
+	fields = binary.BigEndian.AppendUint16(fields, uint16(len(value)))
+	return append(fields, value...)
+}
@@ -0,0 +1,192 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/suyuan32/knife/core/codec"
+	"github.com/suyuan32/knife/cryptox/symmetric/method"
+	"github.com/suyuan32/knife/cryptox/symmetric/mode"
+	"github.com/suyuan32/knife/cryptox/symmetric/padding"
+)
+
+func TestCryptoS_Envelope(t *testing.T) {
+	testStr := bytes.Repeat([]byte{'a'}, 120)
+	key := bytes.Repeat([]byte{'c'}, 16)
+
+	tests := []struct {
+		method  method.MethodType
+		mode    mode.ModeType
+		padding padding.PaddingType
+		iv      []byte
+	}{
+		{method: method.AES, mode: mode.CBC, padding: padding.PKCS7, iv: bytes.Repeat([]byte{'b'}, 16)},
+		{method: method.SM4, mode: mode.CTR, padding: padding.PKCS7, iv: bytes.Repeat([]byte{'b'}, 16)},
+		{method: method.AES, mode: mode.GCM, iv: bytes.Repeat([]byte{'b'}, 16)},
+	}
+
+	for _, tt := range tests {
+		c := NewCryptoS()
+		envelope, err := c.InputFromBytes(testStr).
+			WithMethod(tt.method).
+			WithMode(tt.mode).
+			WithPadding(tt.padding).
+			WithNonceSize(len(tt.iv)).
+			WithIV(tt.iv).
+			WithKey(key).
+			WithKeyID("2023-q1").
+			Encrypt().
+			ToEnvelope()
+		assert.Nil(t, err)
+
+		c = NewCryptoS()
+		c.FromEnvelope(envelope)
+		assert.Nil(t, c.Errors)
+		assert.Equal(t, tt.method, c.Method)
+		assert.Equal(t, tt.mode, c.Mode)
+		assert.Equal(t, tt.padding, c.Padding)
+		assert.Equal(t, tt.iv, c.IV)
+		assert.Equal(t, "2023-q1", c.KeyID)
+
+		result, err := c.WithKey(key).Decrypt().ToBytes()
+		assert.Nil(t, err)
+		assert.Equal(t, testStr, result)
+	}
+
+	// the random IV is recorded in the header instead of the ciphertext
+	c := NewCryptoS()
+	envelope, err := c.InputFromBytes(testStr).
+		WithMode(mode.GCM).
+		WithKey(key).
+		WithRandomIV().
+		Encrypt().
+		ToEnvelope()
+	assert.Nil(t, err)
+
+	c = NewCryptoS()
+	result, err := c.WithRandomIV().FromEnvelope(envelope).WithKey(key).Decrypt().ToBytes()
+	assert.Nil(t, err)
+	assert.Equal(t, testStr, result)
+
+	// string variants
+	c = NewCryptoS()
+	c.InputFromBytes(testStr).
+		WithIV(bytes.Repeat([]byte{'b'}, 16)).
+		WithKey(key).
+		WithPadding(padding.PKCS7).
+		Encrypt()
+
+	base64Result, err := c.ToEnvelopeBase64String()
+	assert.Nil(t, err)
+	hexResult, err := c.ToEnvelopeHexString()
+	assert.Nil(t, err)
+	urlResult, err := c.ToEnvelopeEncodedString(codec.Base64RawURL)
+	assert.Nil(t, err)
+
+	c = NewCryptoS()
+	result, err = c.FromEnvelopeBase64String(base64Result).WithKey(key).Decrypt().ToBytes()
+	assert.Nil(t, err)
+	assert.Equal(t, testStr, result)
+	assert.Equal(t, "", c.KeyID)
+
+	c = NewCryptoS()
+	result, err = c.FromEnvelopeHexString(hexResult).WithKey(key).Decrypt().ToBytes()
+	assert.Nil(t, err)
+	assert.Equal(t, testStr, result)
+
+	c = NewCryptoS()
+	result, err = c.FromEnvelopeEncodedString(urlResult, codec.Base64RawURL).WithKey(key).Decrypt().ToBytes()
+	assert.Nil(t, err)
+	assert.Equal(t, testStr, result)
+
+	// errors are returned
+	c = NewCryptoS()
+	c.Encrypt()
+	_, err = c.ToEnvelope()
+	assert.NotNil(t, err)
+	_, err = c.ToEnvelopeBase64String()
+	assert.NotNil(t, err)
+	_, err = c.ToEnvelopeHexString()
+	assert.NotNil(t, err)
+}
+
+func TestCryptoS_FromEnvelope(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{
+			name:    "too short",
+			data:    []byte("KNFE"),
+			wantErr: true,
+		},
+		{
+			name:    "wrong magic",
+			data:    []byte{'K', 'N', 'F', 'F', 1, 1, 1, 4, 0, 0},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported version",
+			data:    []byte{'K', 'N', 'F', 'E', 9, 1, 1, 4, 0, 0},
+			wantErr: true,
+		},
+		{
+			name:    "fields too long",
+			data:    []byte{'K', 'N', 'F', 'E', 1, 1, 1, 4, 0, 5, 1},
+			wantErr: true,
+		},
+		{
+			name:    "truncated field header",
+			data:    []byte{'K', 'N', 'F', 'E', 1, 1, 1, 4, 0, 2, 1, 0},
+			wantErr: true,
+		},
+		{
+			name:    "truncated field value",
+			data:    []byte{'K', 'N', 'F', 'E', 1, 1, 1, 4, 0, 4, 1, 0, 5, 1},
+			wantErr: true,
+		},
+		{
+			name:    "invalid key derivation parameters",
+			data:    []byte{'K', 'N', 'F', 'E', 1, 1, 1, 4, 0, 4, 3, 0, 1, '$'},
+			wantErr: true,
+		},
+		{
+			name:    "unknown field is skipped",
+			data:    []byte{'K', 'N', 'F', 'E', 1, 1, 1, 4, 0, 5, 99, 0, 2, 1, 1, 'a'},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCryptoS()
+			c.FromEnvelope(tt.data)
+			assert.Equal(t, tt.wantErr, errors.Is(c.Errors, ErrInvalidEnvelope))
+		})
+	}
+
+	c := NewCryptoS()
+	c.FromEnvelopeBase64String("!")
+	assert.NotNil(t, c.Errors)
+
+	c = NewCryptoS()
+	c.FromEnvelopeHexString("!")
+	assert.NotNil(t, c.Errors)
+}
@@ -0,0 +1,124 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"crypto/cipher"
+	"crypto/hmac"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/suyuan32/knife/cryptox/symmetric/mac"
+)
+
+// The info used by HKDF to derive the separate keys of encrypt-then-MAC.
+const (
+	etmEncryptionInfo = "knife encrypt-then-mac encryption key"
+	etmMACInfo        = "knife encrypt-then-mac mac key"
+)
+
+// etmMACKeySize is the size of the MAC key derived for encrypt-then-MAC.
+const etmMACKeySize = 32
+
+// newBlockCipher returns the cipher block and the MAC key. If MAC is set, the encryption key and MAC key are derived
+// from the key by HKDF, otherwise the key is used directly and the MAC key is nil.
+func (s *CryptoS) newBlockCipher() (cipher.Block, []byte, error) {
+	if s.MAC == 0 {
+		block, err := s.NewCipher()
+		return block, nil, err
+	}
+
+	key := s.key()
+	if len(key) == 0 {
+		return nil, nil, errors.New("the key cannot be empty")
+	}
+
+	h, err := mac.HashFunc(s.MAC)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	encryptionKey := make([]byte, len(key))
+	if _, err = io.ReadFull(hkdf.New(h, key, nil, []byte(etmEncryptionInfo)), encryptionKey); err != nil {
+		return nil, nil, err
+	}
+
+	macKey := make([]byte, etmMACKeySize)
+	if _, err = io.ReadFull(hkdf.New(h, key, nil, []byte(etmMACInfo)), macKey); err != nil {
+		return nil, nil, err
+	}
+
+	block, err := s.newBlock(encryptionKey)
+	return block, macKey, err
+}
+
+// computeMAC returns the tag of the associated data, IV and ciphertext.
+func (s *CryptoS) computeMAC(macKey, ciphertext []byte) ([]byte, error) {
+	h, err := mac.New(s.MAC, macKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var length [8]byte
+	binary.BigEndian.PutUint64(length[:], uint64(len(s.AdditionalData)))
+
+	h.Write(length[:])
+	h.Write(s.AdditionalData)
+	h.Write(s.IV)
+	h.Write(ciphertext)
+
+	return h.Sum(nil), nil
+}
+
+// appendMAC appends the tag to the output data.
+func (s *CryptoS) appendMAC(macKey []byte) error {
+	tag, err := s.computeMAC(macKey, s.OutputData)
+	if err != nil {
+		return err
+	}
+
+	s.OutputData = append(s.OutputData, tag...)
+	return nil
+}
+
+// verifyMAC verifies the tag at the end of the input data in constant time and removes it.
+func (s *CryptoS) verifyMAC(macKey []byte) error {
+	h, err := mac.New(s.MAC, macKey)
+	if err != nil {
+		return err
+	}
+
+	tagSize := h.Size()
+	if len(s.InputData) < tagSize {
+		return ErrAuthenticationFailed
+	}
+
+	ciphertext, tag := s.InputData[:len(s.InputData)-tagSize], s.InputData[len(s.InputData)-tagSize:]
+
+	expected, err := s.computeMAC(macKey, ciphertext)
+	if err != nil {
+		return err
+	}
+
+	if !hmac.Equal(tag, expected) {
+		return ErrAuthenticationFailed
+	}
+
+	s.InputData = ciphertext
+	return nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/suyuan32/knife/cryptox/symmetric/mac"
+	"github.com/suyuan32/knife/cryptox/symmetric/method"
+	"github.com/suyuan32/knife/cryptox/symmetric/mode"
+	"github.com/suyuan32/knife/cryptox/symmetric/padding"
+)
+
+func TestCryptoS_MAC(t *testing.T) {
+	plaintext := bytes.Repeat([]byte{'a'}, 100)
+	for _, m := range []mode.ModeType{mode.CBC, mode.CTR} {
+		for _, macType := range []mac.MACType{mac.HMACSHA256, mac.HMACSM3} {
+			enc := newTestCryptoS().WithMethod(method.SM4).WithMode(m).WithPadding(padding.PKCS7).WithMAC(macType).
+				WithRandomIV().WithAdditionalData([]byte("header")).
+				KeyFromString("1234567890123456").InputFromBytes(plaintext).Encrypt()
+			assert.Nil(t, enc.Errors)
+
+			ciphertext := enc.OutputData
+			assert.Equal(t, 16+112+32, len(ciphertext))
+
+			dec := newTestCryptoS().WithMethod(method.SM4).WithMode(m).WithPadding(padding.PKCS7).WithMAC(macType).
+				WithRandomIV().WithAdditionalData([]byte("header")).
+				KeyFromString("1234567890123456").InputFromBytes(ciphertext).Decrypt()
+			assert.Nil(t, dec.Errors)
+			assert.Equal(t, plaintext, dec.OutputData)
+
+			// the encryption key is derived, so the ciphertext differs from the one without MAC
+			plain := newTestCryptoS().WithMethod(method.SM4).WithMode(m).WithPadding(padding.PKCS7).
+				IVFromBytes(ciphertext[:16]).KeyFromString("1234567890123456").
+				InputFromBytes(plaintext).Encrypt()
+			assert.Nil(t, plain.Errors)
+			assert.NotEqual(t, plain.OutputData, ciphertext[16:128])
+
+			// tampered ciphertext, IV, tag or associated data
+			for _, i := range []int{0, 20, len(ciphertext) - 1} {
+				tampered := bytes.Clone(ciphertext)
+				tampered[i] ^= 1
+
+				dec = newTestCryptoS().WithMethod(method.SM4).WithMode(m).WithPadding(padding.PKCS7).WithMAC(macType).
+					WithRandomIV().WithAdditionalData([]byte("header")).
+					KeyFromString("1234567890123456").InputFromBytes(tampered).Decrypt()
+				assert.True(t, errors.Is(dec.Errors, ErrAuthenticationFailed))
+				assert.Nil(t, dec.OutputData)
+			}
+
+			dec = newTestCryptoS().WithMethod(method.SM4).WithMode(m).WithPadding(padding.PKCS7).WithMAC(macType).
+				WithRandomIV().WithAdditionalData([]byte("other")).
+				KeyFromString("1234567890123456").InputFromBytes(ciphertext).Decrypt()
+			assert.True(t, errors.Is(dec.Errors, ErrAuthenticationFailed))
+
+			dec = newTestCryptoS().WithMethod(method.SM4).WithMode(m).WithPadding(padding.PKCS7).WithMAC(macType).
+				WithRandomIV().KeyFromString("1234567890123456").InputFromBytes(ciphertext[:20]).Decrypt()
+			assert.True(t, errors.Is(dec.Errors, ErrAuthenticationFailed))
+		}
+	}
+
+	// envelope records the MAC
+	enc := newTestCryptoS().WithMAC(mac.HMACSHA256).WithPadding(padding.PKCS7).IVFromString("1234567890123456").
+		KeyFromString("1234567890123456").InputFromString("hello").Encrypt()
+	assert.Nil(t, enc.Errors)
+
+	envelope, err := enc.ToEnvelope()
+	assert.Nil(t, err)
+
+	dec := newTestCryptoS().KeyFromString("1234567890123456").FromEnvelope(envelope).Decrypt()
+	assert.Nil(t, dec.Errors)
+	assert.Equal(t, mac.HMACSHA256, dec.MAC)
+	assert.Equal(t, "hello", string(dec.OutputData))
+
+	// not supported with AEAD or stream
+	enc = newTestCryptoS().WithMode(mode.GCM).WithMAC(mac.HMACSHA256).IVFromString("123456789012").
+		KeyFromString("1234567890123456").InputFromString("hello").Encrypt()
+	assert.NotNil(t, enc.Errors)
+
+	_, err = newTestCryptoS().WithMAC(mac.HMACSHA256).IVFromString("1234567890123456").
+		KeyFromString("1234567890123456").NewEncryptWriter(&bytes.Buffer{})
+	assert.NotNil(t, err)
+}
+
+func newTestCryptoS() *CryptoS {
+	c := NewCryptoS()
+	return &c
+}
@@ -0,0 +1,283 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/suyuan32/knife/core/io/filex"
+	"github.com/suyuan32/knife/cryptox/symmetric/method"
+	"github.com/suyuan32/knife/cryptox/symmetric/mode"
+)
+
+// The encrypted file is split into fixed-size chunks which are sealed separately by the AEAD, its layout is:
+//
+//	magic "KNFF" | version (1 byte) | method (1 byte) | mode (1 byte) | chunk size (4 bytes) |
+//	nonce prefix length (1 byte) | nonce prefix | sealed chunks
+//
+// The nonce of each chunk is nonce prefix | chunk counter (4 bytes) | last chunk flag (1 byte), and the header
+// together with the associated data is authenticated with every chunk. A reordered chunk fails the counter
+// and a truncated file fails the last chunk flag.
+
+// FileVersion is the current version of the encrypted file format.
+const FileVersion = 1
+
+// fileMagic is the magic number at the beginning of the encrypted file.
+var fileMagic = []byte("KNFF")
+
+const (
+	// fileHeaderSize is the size of the fixed header before the nonce prefix.
+	fileHeaderSize = 12
+	// fileChunkSize is the size of plaintext in each chunk.
+	fileChunkSize = 64 * 1024
+	// fileMaxChunkSize is the max chunk size accepted when decrypting.
+	fileMaxChunkSize = 16 * 1024 * 1024
+	// fileNonceSuffixSize is the size of chunk counter and last chunk flag in the nonce.
+	fileNonceSuffixSize = 5
+	// fileMinNoncePrefixSize is the min size of the random nonce prefix.
+	fileMinNoncePrefixSize = 7
+)
+
+// ErrInvalidFile is returned when the encrypted file cannot be parsed.
+var ErrInvalidFile = errors.New("invalid encrypted file")
+
+// EncryptFile encrypts the src file to the dst file in authenticated chunks. It requires an AEAD method or mode
+// such as GCM, the IV is not used because a random nonce prefix is generated for each file.
+// The dst file is written atomically, it is not changed if the encryption fails.
+func (s *CryptoS) EncryptFile(src, dst string) error {
+	aead, err := s.newFileAEAD()
+	if err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	prefix := make([]byte, aead.NonceSize()-fileNonceSuffixSize)
+	if _, err = rand.Read(prefix); err != nil {
+		return fmt.Errorf("failed to generate nonce, error:%s", err)
+	}
+
+	header := make([]byte, fileHeaderSize, fileHeaderSize+len(prefix))
+	copy(header, fileMagic)
+	header[4] = FileVersion
+	header[5] = byte(s.Method)
+	header[6] = byte(s.Mode)
+	binary.BigEndian.PutUint32(header[7:11], fileChunkSize)
+	header[11] = byte(len(prefix))
+	header = append(header, prefix...)
+
+	return filex.WriteFileAtomic(dst, int(info.Mode().Perm()), func(f *os.File) error {
+		w := bufio.NewWriter(f)
+		if _, err := w.Write(header); err != nil {
+			return err
+		}
+
+		additionalData := append(bytes.Clone(header), s.AdditionalData...)
+		nonce := append(bytes.Clone(prefix), make([]byte, fileNonceSuffixSize)...)
+		r := bufio.NewReaderSize(in, fileChunkSize)
+		chunk := make([]byte, fileChunkSize)
+		sealed := make([]byte, 0, fileChunkSize+aead.Overhead())
+
+		for counter := uint64(0); ; counter++ {
+			if counter > math.MaxUint32 {
+				return errors.New("the file is too large")
+			}
+
+			n, last, err := readFileChunk(r, chunk)
+			if err != nil {
+				return err
+			}
+
+			setFileNonce(nonce, uint32(counter), last)
+			sealed = aead.Seal(sealed[:0], nonce, chunk[:n], additionalData)
+			if _, err = w.Write(sealed); err != nil {
+				return err
+			}
+
+			if last {
+				return w.Flush()
+			}
+		}
+	})
+}
+
+// DecryptFile decrypts the src file encrypted by EncryptFile to the dst file. Every chunk is authenticated before
+// it is written, and ErrAuthenticationFailed is returned if the file has been tampered with, reordered or truncated.
+// The dst file is written atomically, it is not changed if the decryption fails.
+func (s *CryptoS) DecryptFile(src, dst string) error {
+	aead, err := s.newFileAEAD()
+	if err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	r := bufio.NewReader(in)
+
+	header := make([]byte, fileHeaderSize)
+	if _, err = io.ReadFull(r, header); err != nil {
+		return ErrInvalidFile
+	}
+
+	if !bytes.Equal(header[:4], fileMagic) {
+		return ErrInvalidFile
+	}
+
+	if version := header[4]; version != FileVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidFile, version)
+	}
+
+	if method.MethodType(header[5]) != s.Method || mode.ModeType(header[6]) != s.Mode {
+		return fmt.Errorf("%w: the method or mode does not match", ErrInvalidFile)
+	}
+
+	chunkSize := binary.BigEndian.Uint32(header[7:11])
+	if chunkSize == 0 || chunkSize > fileMaxChunkSize {
+		return fmt.Errorf("%w: invalid chunk size %d", ErrInvalidFile, chunkSize)
+	}
+
+	if int(header[11]) != aead.NonceSize()-fileNonceSuffixSize {
+		return fmt.Errorf("%w: invalid nonce size", ErrInvalidFile)
+	}
+
+	prefix := make([]byte, header[11])
+	if _, err = io.ReadFull(r, prefix); err != nil {
+		return ErrInvalidFile
+	}
+	header = append(header, prefix...)
+
+	return filex.WriteFileAtomic(dst, int(info.Mode().Perm()), func(f *os.File) error {
+		w := bufio.NewWriter(f)
+
+		additionalData := append(header, s.AdditionalData...)
+		nonce := append(prefix, make([]byte, fileNonceSuffixSize)...)
+		chunk := make([]byte, int(chunkSize)+aead.Overhead())
+		opened := make([]byte, 0, chunkSize)
+
+		for counter := uint64(0); ; counter++ {
+			if counter > math.MaxUint32 {
+				return ErrInvalidFile
+			}
+
+			n, last, err := readFileChunk(r, chunk)
+			if err != nil {
+				return err
+			}
+
+			setFileNonce(nonce, uint32(counter), last)
+			opened, err = aead.Open(opened[:0], nonce, chunk[:n], additionalData)
+			if err != nil {
+				return fmt.Errorf("failed to decrypt chunk %d, error:%w", counter, ErrAuthenticationFailed)
+			}
+
+			if _, err = w.Write(opened); err != nil {
+				return err
+			}
+
+			if last {
+				return w.Flush()
+			}
+		}
+	})
+}
+
+// newFileAEAD returns the AEAD used by the file encryption.
+func (s *CryptoS) newFileAEAD() (aead cipher.AEAD, err error) {
+	s.operate(func() { aead, err = s.fileAEAD() })
+	return aead, err
+}
+
+// fileAEAD validates the configuration and creates the AEAD in the operation started by newFileAEAD.
+func (s *CryptoS) fileAEAD() (cipher.AEAD, error) {
+	if !s.isAEAD() {
+		return nil, errors.New("the file encryption requires an AEAD method or mode")
+	}
+
+	if s.MAC != 0 {
+		return nil, newValidationError("MAC", "the AEAD does not need encrypt-then-MAC")
+	}
+
+	if err := s.validateKey(); err != nil {
+		return nil, fmt.Errorf("failed to validate data, error:%w", err)
+	}
+
+	aead, err := s.NewAEAD()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher from the data, error:%s", err)
+	}
+
+	if aead.NonceSize() < fileMinNoncePrefixSize+fileNonceSuffixSize {
+		return nil, fmt.Errorf("the nonce size must be at least %d bytes for file encryption",
+			fileMinNoncePrefixSize+fileNonceSuffixSize)
+	}
+
+	return aead, nil
+}
+
+// readFileChunk fills buf from r and reports whether it is the last chunk of the file.
+func readFileChunk(r *bufio.Reader, buf []byte) (int, bool, error) {
+	n, err := io.ReadFull(r, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return n, true, nil
+	}
+	if err != nil {
+		return n, false, err
+	}
+
+	if _, err = r.Peek(1); err == io.EOF {
+		return n, true, nil
+	} else if err != nil {
+		return n, false, err
+	}
+
+	return n, false, nil
+}
+
+// setFileNonce sets the chunk counter and last chunk flag at the end of the nonce.
+func setFileNonce(nonce []byte, counter uint32, last bool) {
+	suffix := nonce[len(nonce)-fileNonceSuffixSize:]
+	binary.BigEndian.PutUint32(suffix, counter)
+	suffix[4] = 0
+	if last {
+		suffix[4] = 1
+	}
+}
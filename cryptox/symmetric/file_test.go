@@ -0,0 +1,130 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/suyuan32/knife/cryptox/symmetric/method"
+	"github.com/suyuan32/knife/cryptox/symmetric/mode"
+)
+
+func TestCryptoS_EncryptFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "plain")
+	dst := filepath.Join(dir, "encrypted")
+	result := filepath.Join(dir, "decrypted")
+
+	tests := []struct {
+		name   string
+		method method.MethodType
+		mode   mode.ModeType
+		key    []byte
+	}{
+		{name: "AES-GCM", method: method.AES, mode: mode.GCM, key: bytes.Repeat([]byte{'k'}, 16)},
+		{name: "SM4-GCM", method: method.SM4, mode: mode.GCM, key: bytes.Repeat([]byte{'k'}, 16)},
+		{name: "ChaCha20-Poly1305", method: method.ChaCha20Poly1305, key: bytes.Repeat([]byte{'k'}, 32)},
+		{name: "XChaCha20-Poly1305", method: method.XChaCha20Poly1305, key: bytes.Repeat([]byte{'k'}, 32)},
+	}
+
+	for _, tt := range tests {
+		for _, size := range []int{0, 100, fileChunkSize, 2*fileChunkSize + 5} {
+			plaintext := make([]byte, size)
+			_, _ = rand.Read(plaintext)
+			assert.Nil(t, os.WriteFile(src, plaintext, 0600))
+
+			c := NewCryptoS()
+			c.WithMethod(tt.method).WithMode(tt.mode).WithKey(tt.key).WithAdditionalData([]byte("backup"))
+
+			assert.Nil(t, c.EncryptFile(src, dst), tt.name)
+			assert.Nil(t, c.DecryptFile(dst, result), tt.name)
+
+			data, err := os.ReadFile(result)
+			assert.Nil(t, err)
+			assert.Equal(t, plaintext, data, tt.name)
+		}
+	}
+}
+
+func TestCryptoS_DecryptFile_Tampered(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "plain")
+	dst := filepath.Join(dir, "encrypted")
+	result := filepath.Join(dir, "decrypted")
+
+	plaintext := make([]byte, 3*fileChunkSize+10)
+	_, _ = rand.Read(plaintext)
+	assert.Nil(t, os.WriteFile(src, plaintext, 0600))
+
+	c := NewCryptoS()
+	c.WithMode(mode.GCM).KeyFromString("1234567890123456")
+	assert.Nil(t, c.EncryptFile(src, dst))
+
+	encrypted, err := os.ReadFile(dst)
+	assert.Nil(t, err)
+
+	headerSize := fileHeaderSize + gcmStandardNonceSize - fileNonceSuffixSize
+	sealedSize := fileChunkSize + 16
+	chunk := func(i int) []byte {
+		return encrypted[headerSize+i*sealedSize : headerSize+(i+1)*sealedSize]
+	}
+
+	flipped := bytes.Clone(encrypted)
+	flipped[len(flipped)-1] ^= 1
+
+	var reordered []byte
+	reordered = append(reordered, encrypted[:headerSize]...)
+	reordered = append(reordered, chunk(1)...)
+	reordered = append(reordered, chunk(0)...)
+	reordered = append(reordered, encrypted[headerSize+2*sealedSize:]...)
+
+	tests := map[string][]byte{
+		"flipped":              flipped,
+		"reordered":            reordered,
+		"truncated at chunk":   encrypted[:headerSize+3*sealedSize],
+		"truncated in chunk":   encrypted[:len(encrypted)-5],
+		"only header":          encrypted[:headerSize],
+		"additional chunk":     append(bytes.Clone(encrypted), chunk(0)...),
+		"truncated in header":  encrypted[:5],
+		"invalid magic number": append([]byte("XXXX"), encrypted[4:]...),
+	}
+
+	for name, data := range tests {
+		assert.Nil(t, os.WriteFile(dst, data, 0600))
+
+		err = c.DecryptFile(dst, result)
+		assert.True(t, errors.Is(err, ErrAuthenticationFailed) || errors.Is(err, ErrInvalidFile), name)
+
+		_, err = os.Stat(result)
+		assert.True(t, os.IsNotExist(err), name)
+	}
+
+	// the other associated data
+	assert.Nil(t, os.WriteFile(dst, encrypted, 0600))
+	c.WithAdditionalData([]byte("other"))
+	assert.True(t, errors.Is(c.DecryptFile(dst, result), ErrAuthenticationFailed))
+
+	// AEAD is required
+	c = NewCryptoS()
+	c.KeyFromString("1234567890123456")
+	assert.NotNil(t, c.EncryptFile(src, dst))
+}
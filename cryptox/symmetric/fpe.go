@@ -0,0 +1,60 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"fmt"
+
+	"github.com/suyuan32/knife/cryptox/symmetric/fpe"
+	"github.com/suyuan32/knife/cryptox/symmetric/method"
+)
+
+// NewFF1 returns the format-preserving encryption FF1 with the method and key of CryptoS. The method must be
+// AES or SM4, and the radix is the number of characters in the alphabet such as fpe.DigitAlphabet.
+func (s *CryptoS) NewFF1(alphabet string) (f *fpe.FF1, err error) {
+	s.operate(func() {
+		if err = s.validateFPE(); err != nil {
+			return
+		}
+		f, err = fpe.NewFF1(s.newBlock, s.key(), alphabet)
+	})
+	return f, err
+}
+
+// NewFF31 returns the format-preserving encryption FF3-1 with the method and key of CryptoS. The method must be
+// AES or SM4, and the radix is the number of characters in the alphabet such as fpe.DigitAlphabet.
+func (s *CryptoS) NewFF31(alphabet string) (f *fpe.FF31, err error) {
+	s.operate(func() {
+		if err = s.validateFPE(); err != nil {
+			return
+		}
+		f, err = fpe.NewFF31(s.newBlock, s.key(), alphabet)
+	})
+	return f, err
+}
+
+// validateFPE validates the method and key for the format-preserving encryption.
+func (s *CryptoS) validateFPE() error {
+	if s.Method != method.AES && s.Method != method.SM4 {
+		return fmt.Errorf("failed to validate data, error:%w",
+			newValidationError("method", "the format-preserving encryption only supports AES and SM4"))
+	}
+
+	if err := s.validateKey(); err != nil {
+		return fmt.Errorf("failed to validate data, error:%w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fpe
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// maxTweakSize is the max tweak size of FF1.
+const maxTweakSize = math.MaxUint16
+
+// FF1 is the format-preserving encryption FF1 defined in NIST SP 800-38G. It is safe for concurrent use.
+type FF1 struct {
+	block    cipher.Block
+	alphabet *alphabet
+	minLen   int
+}
+
+// NewFF1 returns FF1 with the block cipher created from the key by newCipher such as aes.NewCipher and
+// sm4.NewCipher. The radix is the number of characters in the alphabet such as DigitAlphabet.
+func NewFF1(newCipher NewCipherFunc, key []byte, alphabet string) (*FF1, error) {
+	block, err := newCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if block.BlockSize() != blockSize {
+		return nil, errors.New("the FF1 requires a 128-bit block cipher")
+	}
+
+	a, err := newAlphabet(alphabet)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FF1{block: block, alphabet: a, minLen: a.minLength()}, nil
+}
+
+// Radix returns the radix of the alphabet.
+func (f *FF1) Radix() int {
+	return f.alphabet.radix
+}
+
+// Encrypt encrypts the plaintext with the tweak, the ciphertext has the same length and alphabet.
+func (f *FF1) Encrypt(plaintext string, tweak []byte) (string, error) {
+	return f.crypt(plaintext, tweak, true)
+}
+
+// Decrypt decrypts the ciphertext with the tweak.
+func (f *FF1) Decrypt(ciphertext string, tweak []byte) (string, error) {
+	return f.crypt(ciphertext, tweak, false)
+}
+
+// crypt runs the ten Feistel rounds of FF1.
+func (f *FF1) crypt(data string, tweak []byte, encrypt bool) (string, error) {
+	x, err := f.alphabet.numerals(data)
+	if err != nil {
+		return "", err
+	}
+
+	n, t, radix := len(x), len(tweak), f.alphabet.radix
+	if n < f.minLen || n > math.MaxUint32 {
+		return "", fmt.Errorf("%w: the length must be at least %d, got %d", ErrInvalidInput, f.minLen, n)
+	}
+
+	if t > maxTweakSize {
+		return "", fmt.Errorf("%w: the tweak size must be at most %d, got %d", ErrInvalidInput, maxTweakSize, t)
+	}
+
+	u := n / 2
+	v := n - u
+	a, b := x[:u], x[u:]
+
+	size := int(math.Ceil(math.Ceil(float64(v)*log2(radix)) / 8))
+	d := 4*((size+3)/4) + 4
+
+	p := []byte{1, 2, 1, 0, 0, 0, 10, byte(u), 0, 0, 0, 0, 0, 0, 0, 0}
+	p[3], p[4], p[5] = byte(radix>>16), byte(radix>>8), byte(radix)
+	binary.BigEndian.PutUint32(p[8:12], uint32(n))
+	binary.BigEndian.PutUint32(p[12:16], uint32(t))
+
+	q := make([]byte, t+((-t-size-1)%16+16)%16+1+size)
+	copy(q, tweak)
+
+	modU, modV := pow(radix, u), pow(radix, v)
+	y, c := new(big.Int), new(big.Int)
+	s := make([]byte, (d+15)/16*16)
+
+	for round := 0; round < 10; round++ {
+		i := round
+		if !encrypt {
+			i = 9 - round
+		}
+
+		m, mod := u, modU
+		if i%2 == 1 {
+			m, mod = v, modV
+		}
+
+		// the other half is the input of the round function
+		in := b
+		if !encrypt {
+			in = a
+		}
+
+		q[len(q)-size-1] = byte(i)
+		copy(q[len(q)-size:], fillBytes(num(in, radix), size))
+
+		r := f.prf(append(p, q...))
+		copy(s, r)
+		for j := 1; j*16 < d; j++ {
+			block := append([]byte{}, r...)
+			binary.BigEndian.PutUint64(block[8:], binary.BigEndian.Uint64(r[8:])^uint64(j))
+			f.block.Encrypt(s[j*16:], block)
+		}
+		y.SetBytes(s[:d])
+
+		if encrypt {
+			c.Add(num(a, radix), y)
+			c.Mod(c, mod)
+			a, b = b, str(c, radix, m)
+		} else {
+			c.Sub(num(b, radix), y)
+			c.Mod(c, mod)
+			a, b = str(c, radix, m), a
+		}
+	}
+
+	return f.alphabet.string(append(append([]uint16{}, a...), b...)), nil
+}
+
+// prf is the CBC-MAC of the data with zero IV.
+func (f *FF1) prf(data []byte) []byte {
+	r := make([]byte, blockSize)
+	for len(data) > 0 {
+		for i := 0; i < blockSize; i++ {
+			r[i] ^= data[i]
+		}
+		f.block.Encrypt(r, r)
+		data = data[blockSize:]
+	}
+	return r
+}
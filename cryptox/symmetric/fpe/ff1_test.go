@@ -0,0 +1,147 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fpe
+
+import (
+	"crypto/aes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/suyuan32/knife/cryptox/symmetric/method/sm4"
+)
+
+func TestFF1(t *testing.T) {
+	// NIST SP 800-38G FF1 samples
+	tests := []struct {
+		name     string
+		key      string
+		alphabet string
+		tweak    string
+		input    string
+		want     string
+	}{
+		{
+			name:     "sample 1",
+			key:      "2b7e151628aed2a6abf7158809cf4f3c",
+			alphabet: DigitAlphabet,
+			input:    "0123456789",
+			want:     "2433477484",
+		},
+		{
+			name:     "sample 2",
+			key:      "2b7e151628aed2a6abf7158809cf4f3c",
+			alphabet: DigitAlphabet,
+			tweak:    "39383736353433323130",
+			input:    "0123456789",
+			want:     "6124200773",
+		},
+		{
+			name:     "sample 3",
+			key:      "2b7e151628aed2a6abf7158809cf4f3c",
+			alphabet: LowerAlphanumericAlphabet,
+			tweak:    "3737373770717273373737",
+			input:    "0123456789abcdefghi",
+			want:     "a9tv40mll9kdu509eum",
+		},
+		{
+			name:     "sample 4",
+			key:      "2b7e151628aed2a6abf7158809cf4f3cef4359d8d580aa4f",
+			alphabet: DigitAlphabet,
+			input:    "0123456789",
+			want:     "2830668132",
+		},
+		{
+			name:     "sample 7",
+			key:      "2b7e151628aed2a6abf7158809cf4f3cef4359d8d580aa4f7f036d6f04fc6a94",
+			alphabet: DigitAlphabet,
+			input:    "0123456789",
+			want:     "6657667009",
+		},
+		{
+			name:     "sample 9",
+			key:      "2b7e151628aed2a6abf7158809cf4f3cef4359d8d580aa4f7f036d6f04fc6a94",
+			alphabet: LowerAlphanumericAlphabet,
+			tweak:    "3737373770717273373737",
+			input:    "0123456789abcdefghi",
+			want:     "xs8a0azh2avyalyzuwd",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, _ := hex.DecodeString(tt.key)
+			tweak, _ := hex.DecodeString(tt.tweak)
+
+			f, err := NewFF1(aes.NewCipher, key, tt.alphabet)
+			assert.Nil(t, err)
+
+			result, err := f.Encrypt(tt.input, tweak)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, result)
+
+			result, err = f.Decrypt(tt.want, tweak)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.input, result)
+		})
+	}
+}
+
+func TestFF1_SM4(t *testing.T) {
+	f, err := NewFF1(sm4.NewCipher, []byte("1234567890123456"), DigitAlphabet)
+	assert.Nil(t, err)
+	assert.Equal(t, 10, f.Radix())
+
+	for _, input := range []string{"13800138000", "6222021234567890123", "123456"} {
+		result, err := f.Encrypt(input, []byte("phone"))
+		assert.Nil(t, err)
+		assert.Equal(t, len(input), len(result))
+		assert.NotEqual(t, input, result)
+
+		other, err := f.Encrypt(input, []byte("card"))
+		assert.Nil(t, err)
+		assert.NotEqual(t, result, other)
+
+		result, err = f.Decrypt(result, []byte("phone"))
+		assert.Nil(t, err)
+		assert.Equal(t, input, result)
+	}
+
+	// unicode alphabet
+	f, err = NewFF1(sm4.NewCipher, []byte("1234567890123456"), "零一二三四五六七八九")
+	assert.Nil(t, err)
+
+	result, err := f.Encrypt("一二三四五六七", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 7, len([]rune(result)))
+
+	result, err = f.Decrypt(result, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "一二三四五六七", result)
+
+	// invalid input
+	_, err = f.Encrypt("12345", nil)
+	assert.True(t, errors.Is(err, ErrInvalidInput))
+
+	_, err = f.Encrypt("一二三", nil)
+	assert.True(t, errors.Is(err, ErrInvalidInput))
+
+	_, err = NewFF1(sm4.NewCipher, []byte("1234567890123456"), "1")
+	assert.NotNil(t, err)
+
+	_, err = NewFF1(sm4.NewCipher, []byte("1234567890123456"), "112")
+	assert.NotNil(t, err)
+}
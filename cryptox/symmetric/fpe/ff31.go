@@ -0,0 +1,158 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fpe
+
+import (
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+const (
+	// FF31TweakSize is the tweak size of FF3-1, it is 56 bits.
+	FF31TweakSize = 7
+	// ff3TweakSize is the tweak size of the original FF3, it is 64 bits.
+	ff3TweakSize = 8
+)
+
+// FF31 is the format-preserving encryption FF3-1 defined in NIST SP 800-38G Revision 1. It is safe for
+// concurrent use.
+type FF31 struct {
+	block    cipher.Block
+	alphabet *alphabet
+	minLen   int
+	maxLen   int
+}
+
+// NewFF31 returns FF3-1 with the block cipher created by newCipher such as aes.NewCipher and sm4.NewCipher.
+// The key is reversed before creating the block cipher as required by FF3-1. The radix is the number of
+// characters in the alphabet such as DigitAlphabet.
+func NewFF31(newCipher NewCipherFunc, key []byte, alphabet string) (*FF31, error) {
+	block, err := newCipher(reverse(key))
+	if err != nil {
+		return nil, err
+	}
+
+	if block.BlockSize() != blockSize {
+		return nil, errors.New("the FF3-1 requires a 128-bit block cipher")
+	}
+
+	a, err := newAlphabet(alphabet)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FF31{
+		block:    block,
+		alphabet: a,
+		minLen:   a.minLength(),
+		maxLen:   2 * int(math.Floor(96/log2(a.radix))),
+	}, nil
+}
+
+// Radix returns the radix of the alphabet.
+func (f *FF31) Radix() int {
+	return f.alphabet.radix
+}
+
+// Encrypt encrypts the plaintext with the 7 bytes tweak, the ciphertext has the same length and alphabet.
+func (f *FF31) Encrypt(plaintext string, tweak []byte) (string, error) {
+	if len(tweak) != FF31TweakSize {
+		return "", fmt.Errorf("%w: the tweak size must be %d, got %d", ErrInvalidInput, FF31TweakSize, len(tweak))
+	}
+
+	return f.crypt(plaintext, expandTweak(tweak), true)
+}
+
+// Decrypt decrypts the ciphertext with the 7 bytes tweak.
+func (f *FF31) Decrypt(ciphertext string, tweak []byte) (string, error) {
+	if len(tweak) != FF31TweakSize {
+		return "", fmt.Errorf("%w: the tweak size must be %d, got %d", ErrInvalidInput, FF31TweakSize, len(tweak))
+	}
+
+	return f.crypt(ciphertext, expandTweak(tweak), false)
+}
+
+// expandTweak expands the 56 bits tweak of FF3-1 to the 64 bits tweak of FF3, so that the left half is
+// T[0..27] || 0000 and the right half is T[32..55] || T[28..31] || 0000.
+func expandTweak(tweak []byte) []byte {
+	return []byte{
+		tweak[0], tweak[1], tweak[2], tweak[3] & 0xf0,
+		tweak[4], tweak[5], tweak[6], tweak[3] << 4,
+	}
+}
+
+// crypt runs the eight Feistel rounds of FF3 with the 64 bits tweak.
+func (f *FF31) crypt(data string, tweak []byte, encrypt bool) (string, error) {
+	x, err := f.alphabet.numerals(data)
+	if err != nil {
+		return "", err
+	}
+
+	n, radix := len(x), f.alphabet.radix
+	if n < f.minLen || n > f.maxLen {
+		return "", fmt.Errorf("%w: the length must be between %d and %d, got %d", ErrInvalidInput, f.minLen,
+			f.maxLen, n)
+	}
+
+	u := (n + 1) / 2
+	v := n - u
+	a, b := x[:u], x[u:]
+	tl, tr := tweak[:4], tweak[4:ff3TweakSize]
+
+	modU, modV := pow(radix, u), pow(radix, v)
+	y, c := new(big.Int), new(big.Int)
+	p := make([]byte, blockSize)
+
+	for round := 0; round < 8; round++ {
+		i := round
+		if !encrypt {
+			i = 7 - round
+		}
+
+		m, mod, w := u, modU, tr
+		if i%2 == 1 {
+			m, mod, w = v, modV, tl
+		}
+
+		// the other half is the input of the round function
+		in := b
+		if !encrypt {
+			in = a
+		}
+
+		copy(p, w)
+		p[3] ^= byte(i)
+		copy(p[4:], fillBytes(num(reverse(in), radix), 12))
+
+		s := reverse(p)
+		f.block.Encrypt(s, s)
+		y.SetBytes(reverse(s))
+
+		if encrypt {
+			c.Add(num(reverse(a), radix), y)
+			c.Mod(c, mod)
+			a, b = b, reverse(str(c, radix, m))
+		} else {
+			c.Sub(num(reverse(b), radix), y)
+			c.Mod(c, mod)
+			a, b = reverse(str(c, radix, m)), a
+		}
+	}
+
+	return f.alphabet.string(append(append([]uint16{}, a...), b...)), nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fpe
+
+import (
+	"crypto/aes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/suyuan32/knife/cryptox/symmetric/method/sm4"
+)
+
+func TestFF31_FF3KnownAnswer(t *testing.T) {
+	// NIST FF3 samples, FF3-1 runs the same rounds with the tweak expanded to 64 bits
+	tests := []struct {
+		name  string
+		key   string
+		tweak string
+		input string
+		want  string
+	}{
+		{
+			name:  "sample 1",
+			key:   "ef4359d8d580aa4f7f036d6f04fc6a94",
+			tweak: "d8e7920afa330a73",
+			input: "890121234567890000",
+			want:  "750918814058654607",
+		},
+		{
+			name:  "sample 2",
+			key:   "ef4359d8d580aa4f7f036d6f04fc6a94",
+			tweak: "9a768a92f60e12d8",
+			input: "890121234567890000",
+			want:  "018989839189395384",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, _ := hex.DecodeString(tt.key)
+			tweak, _ := hex.DecodeString(tt.tweak)
+
+			f, err := NewFF31(aes.NewCipher, key, DigitAlphabet)
+			assert.Nil(t, err)
+
+			result, err := f.crypt(tt.input, tweak, true)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, result)
+
+			result, err = f.crypt(tt.want, tweak, false)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.input, result)
+		})
+	}
+}
+
+func TestFF31(t *testing.T) {
+	key, _ := hex.DecodeString("2de79d232df5585d68ce47882ae256d6")
+	tweak, _ := hex.DecodeString("cbd09280979564")
+
+	f, err := NewFF31(aes.NewCipher, key, DigitAlphabet)
+	assert.Nil(t, err)
+
+	result, err := f.Encrypt("3992520240", tweak)
+	assert.Nil(t, err)
+	assert.Equal(t, "8901801106", result)
+
+	result, err = f.Decrypt("8901801106", tweak)
+	assert.Nil(t, err)
+	assert.Equal(t, "3992520240", result)
+}
+
+func TestFF31_SM4(t *testing.T) {
+	f, err := NewFF31(sm4.NewCipher, []byte("1234567890123456"), AlphanumericAlphabet)
+	assert.Nil(t, err)
+	assert.Equal(t, 62, f.Radix())
+
+	tweak := []byte("abcdefg")
+	for _, input := range []string{"Ryan2023", "abcdefghijklmnopqrstuvwxyz012"} {
+		result, err := f.Encrypt(input, tweak)
+		assert.Nil(t, err)
+		assert.Equal(t, len(input), len(result))
+		assert.NotEqual(t, input, result)
+
+		result, err = f.Decrypt(result, tweak)
+		assert.Nil(t, err)
+		assert.Equal(t, input, result)
+	}
+
+	// the tweak must be 56 bits
+	_, err = f.Encrypt("Ryan2023", []byte("abcdefgh"))
+	assert.True(t, errors.Is(err, ErrInvalidInput))
+
+	// the length is limited by 2 * floor(log_radix(2^96))
+	_, err = f.Encrypt("abcdefghijklmnopqrstuvwxyz0123456789", tweak)
+	assert.True(t, errors.Is(err, ErrInvalidInput))
+}
@@ -0,0 +1,157 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fpe implements the format-preserving encryption FF1 and FF3-1 defined in NIST SP 800-38G.
+// The ciphertext has the same length and alphabet as the plaintext, so it fits existing schemas of
+// numeric identifiers such as ID numbers, phone numbers and bank cards.
+package fpe
+
+import (
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// The common alphabets of the numeral strings.
+const (
+	// DigitAlphabet is the alphabet of decimal numbers, its radix is 10.
+	DigitAlphabet = "0123456789"
+	// LowerAlphanumericAlphabet is the alphabet of digits and lower case letters, its radix is 36.
+	LowerAlphanumericAlphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+	// AlphanumericAlphabet is the alphabet of digits and letters, its radix is 62.
+	AlphanumericAlphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+)
+
+const (
+	// blockSize is the block size required by FF1 and FF3-1.
+	blockSize = 16
+	// maxRadix is the max radix allowed by NIST SP 800-38G.
+	maxRadix = 1 << 16
+	// minDomainSize is the min domain size radix^minlen required by NIST SP 800-38G.
+	minDomainSize = 1000000
+)
+
+// NewCipherFunc creates a 128-bit block cipher from the key such as aes.NewCipher and sm4.NewCipher.
+type NewCipherFunc func(key []byte) (cipher.Block, error)
+
+// ErrInvalidInput is returned when the input contains characters out of the alphabet or its length is
+// out of the range.
+var ErrInvalidInput = errors.New("invalid input")
+
+// alphabet maps the characters to the numerals.
+type alphabet struct {
+	chars   []rune
+	indexes map[rune]uint16
+	radix   int
+}
+
+// newAlphabet returns the alphabet whose radix is the number of the characters.
+func newAlphabet(chars string) (*alphabet, error) {
+	runes := []rune(chars)
+	if len(runes) < 2 || len(runes) > maxRadix {
+		return nil, fmt.Errorf("the radix must be between 2 and %d, got %d", maxRadix, len(runes))
+	}
+
+	indexes := make(map[rune]uint16, len(runes))
+	for i, v := range runes {
+		if _, ok := indexes[v]; ok {
+			return nil, fmt.Errorf("the alphabet has duplicate character %q", v)
+		}
+		indexes[v] = uint16(i)
+	}
+
+	return &alphabet{chars: runes, indexes: indexes, radix: len(runes)}, nil
+}
+
+// minLength returns the min length of numeral strings which satisfies radix^minlen >= 1000000.
+func (a *alphabet) minLength() int {
+	length, domain := 0, 1
+	for domain < minDomainSize {
+		domain *= a.radix
+		length++
+	}
+	if length < 2 {
+		length = 2
+	}
+	return length
+}
+
+// numerals converts the string to numerals.
+func (a *alphabet) numerals(data string) ([]uint16, error) {
+	result := make([]uint16, 0, len(data))
+	for _, v := range data {
+		index, ok := a.indexes[v]
+		if !ok {
+			return nil, fmt.Errorf("%w: the character %q is not in the alphabet", ErrInvalidInput, v)
+		}
+		result = append(result, index)
+	}
+	return result, nil
+}
+
+// string converts the numerals to string.
+func (a *alphabet) string(numerals []uint16) string {
+	result := make([]rune, len(numerals))
+	for i, v := range numerals {
+		result[i] = a.chars[v]
+	}
+	return string(result)
+}
+
+// num returns the number represented by the numerals in the radix, the most significant numeral is first.
+func num(numerals []uint16, radix int) *big.Int {
+	result, r := new(big.Int), big.NewInt(int64(radix))
+	for _, v := range numerals {
+		result.Mul(result, r)
+		result.Add(result, big.NewInt(int64(v)))
+	}
+	return result
+}
+
+// str returns the m numerals of x in the radix, the most significant numeral is first.
+func str(x *big.Int, radix, m int) []uint16 {
+	result := make([]uint16, m)
+	x, r, mod := new(big.Int).Set(x), big.NewInt(int64(radix)), new(big.Int)
+	for i := m - 1; i >= 0; i-- {
+		x.DivMod(x, r, mod)
+		result[i] = uint16(mod.Uint64())
+	}
+	return result
+}
+
+// pow returns radix^m.
+func pow(radix, m int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(int64(radix)), big.NewInt(int64(m)), nil)
+}
+
+// reverse returns the reversed copy of the slice.
+func reverse[T any](data []T) []T {
+	result := make([]T, len(data))
+	for i, v := range data {
+		result[len(data)-1-i] = v
+	}
+	return result
+}
+
+// fillBytes returns x as a big-endian byte slice of the size.
+func fillBytes(x *big.Int, size int) []byte {
+	return x.FillBytes(make([]byte, size))
+}
+
+// log2 returns log2(radix).
+func log2(radix int) float64 {
+	return math.Log2(float64(radix))
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/suyuan32/knife/core/util/idcard"
+	"github.com/suyuan32/knife/cryptox/symmetric/fpe"
+	"github.com/suyuan32/knife/cryptox/symmetric/method"
+)
+
+func TestCryptoS_FPE(t *testing.T) {
+	c := NewCryptoS()
+	ff1, err := c.WithMethod(method.SM4).KeyFromString("1234567890123456").NewFF1(fpe.DigitAlphabet)
+	assert.Nil(t, err)
+
+	// encrypt the county code and the sequence code of the ID number, the province and birthday are kept
+	// and the check code is computed again, so the encrypted ID number is still valid
+	id := "11010519491231002X"
+	encrypted, err := ff1.Encrypt(id[2:6]+id[14:17], []byte("idcard"))
+	assert.Nil(t, err)
+
+	card := idcard.ChineseID{Id: id[:2] + encrypted[:4] + id[6:14] + encrypted[4:] + "0"}
+	card.Id = card.Id[:17] + string(card.GetCheckCode())
+	assert.True(t, card.IsValidCard())
+	assert.NotEqual(t, id, card.Id)
+
+	decrypted, err := ff1.Decrypt(card.Id[2:6]+card.Id[14:17], []byte("idcard"))
+	assert.Nil(t, err)
+	assert.Equal(t, id[2:6]+id[14:17], decrypted)
+
+	c = NewCryptoS()
+	ff31, err := c.KeyFromString("1234567890123456").NewFF31(fpe.DigitAlphabet)
+	assert.Nil(t, err)
+
+	phone, err := ff31.Encrypt("13800138000", []byte("tweak56"))
+	assert.Nil(t, err)
+	assert.Equal(t, 11, len(phone))
+
+	phone, err = ff31.Decrypt(phone, []byte("tweak56"))
+	assert.Nil(t, err)
+	assert.Equal(t, "13800138000", phone)
+
+	// only AES and SM4 are supported
+	c = NewCryptoS()
+	_, err = c.WithMethod(method.TEA).KeyFromString("1234567890123456").NewFF1(fpe.DigitAlphabet)
+	assert.NotNil(t, err)
+
+	c = NewCryptoS()
+	_, err = c.KeyFromString("short").NewFF31(fpe.DigitAlphabet)
+	assert.NotNil(t, err)
+}
@@ -15,9 +15,9 @@
 package symmetric
 
 import (
-	"encoding/base64"
-	"encoding/hex"
 	"errors"
+
+	"github.com/suyuan32/knife/core/codec"
 )
 
 // InputFromBytes set input data from byte slice.
@@ -34,15 +34,17 @@ func (s *CryptoS) InputFromString(data string) *CryptoS {
 
 // InputFromBase64String set input data from base64 string.
 func (s *CryptoS) InputFromBase64String(data string) *CryptoS {
-	result, err := base64.StdEncoding.DecodeString(data)
-	s.Errors = errors.Join(s.Errors, err)
-	s.InputData = result
-	return s
+	return s.InputFromEncodedString(data, codec.Base64Std)
 }
 
 // InputFromHexString set input data from hex string.
 func (s *CryptoS) InputFromHexString(data string) *CryptoS {
-	result, err := hex.DecodeString(data)
+	return s.InputFromEncodedString(data, codec.Hex)
+}
+
+// InputFromEncodedString set input data from string encoded by the encoding such as codec.Base64RawURL.
+func (s *CryptoS) InputFromEncodedString(data string, encoding codec.Encoding) *CryptoS {
+	result, err := encoding.DecodeString(data)
 	s.Errors = errors.Join(s.Errors, err)
 	s.InputData = result
 	return s
@@ -0,0 +1,122 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/suyuan32/knife/cryptox/symmetric/method"
+	"github.com/suyuan32/knife/cryptox/symmetric/mode"
+	"github.com/suyuan32/knife/cryptox/symmetric/padding"
+)
+
+func TestCryptoS_Insecure(t *testing.T) {
+	// the expected results are generated by openssl enc
+	tests := []struct {
+		name   string
+		method method.MethodType
+		key    string
+		want   string
+	}{
+		{
+			name:   "DES-CBC",
+			method: method.DES,
+			key:    "0123456789abcdef",
+			want:   "dcfed57f528c100bcbc3e86cff76f6b88410405aee7a7aab",
+		},
+		{
+			name:   "DES-EDE3-CBC",
+			method: method.TripleDES,
+			key:    "0123456789abcdeffedcba987654321089abcdef01234567",
+			want:   "e38abd7b788b46ec960a67ad41295b4e5b4356ce5bbb39f2",
+		},
+		{
+			name:   "DES-EDE-CBC",
+			method: method.TripleDES,
+			key:    "0123456789abcdeffedcba9876543210",
+			want:   "8e82d21a5fcd7b21106b0393aaf0b4578b2414ecb816da0b",
+		},
+		{
+			name:   "BF-CBC",
+			method: method.Blowfish,
+			key:    "0123456789abcdeffedcba9876543210",
+			want:   "2d2963fb220d59e10b9c8482e41a743d5784cceaa662037c",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCryptoS()
+			result, err := c.InputFromString("hello legacy pos").
+				WithMethod(tt.method).
+				WithMode(mode.CBC).
+				WithPadding(padding.PKCS7).
+				KeyFromHexString(tt.key).
+				IVFromHexString("0102030405060708").
+				AllowInsecure().
+				Encrypt().
+				ToHexString()
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, result)
+
+			c = NewCryptoS()
+			result, err = c.InputFromHexString(tt.want).
+				WithMethod(tt.method).
+				WithMode(mode.CBC).
+				WithPadding(padding.PKCS7).
+				KeyFromHexString(tt.key).
+				IVFromHexString("0102030405060708").
+				AllowInsecure().
+				Decrypt().
+				ToString()
+			assert.Nil(t, err)
+			assert.Equal(t, "hello legacy pos", result)
+
+			// rejected without AllowInsecure
+			c = NewCryptoS()
+			c.InputFromHexString(tt.want).
+				WithMethod(tt.method).
+				WithPadding(padding.PKCS7).
+				KeyFromHexString(tt.key).
+				IVFromHexString("0102030405060708").
+				Decrypt()
+
+			var validationErr *ValidationError
+			assert.True(t, errors.As(c.Errors, &validationErr))
+			assert.Equal(t, "method", validationErr.Field)
+			assert.True(t, strings.Contains(c.Errors.Error(), tt.method.String()))
+
+			_, err = c.NewCipher()
+			assert.NotNil(t, err)
+			assert.True(t, strings.Contains(err.Error(), tt.method.String()))
+		})
+	}
+
+	c := NewCryptoS()
+	_, err := c.WithMethod(method.Blowfish).KeyFromString("abc").IVFromString("12345678").AllowInsecure().Build()
+	assert.NotNil(t, err)
+}
+
+func TestMethodType_String(t *testing.T) {
+	assert.Equal(t, "AES", method.AES.String())
+	assert.Equal(t, "3DES", method.TripleDES.String())
+	assert.Equal(t, "XChaCha20-Poly1305", method.XChaCha20Poly1305.String())
+	assert.Equal(t, "MethodType(100)", method.MethodType(100).String())
+	assert.True(t, method.DES.IsInsecure())
+	assert.False(t, method.SM4.IsInsecure())
+}
@@ -15,9 +15,12 @@
 package symmetric
 
 import (
-	"encoding/base64"
-	"encoding/hex"
+	"crypto/rand"
 	"errors"
+	"fmt"
+
+	"github.com/suyuan32/knife/core/codec"
+	"github.com/suyuan32/knife/cryptox/symmetric/mode"
 )
 
 // IVFromBytes set IV data from byte slice.
@@ -34,16 +37,64 @@ func (s *CryptoS) IVFromString(data string) *CryptoS {
 
 // IVFromBase64String set IV data from base64 string.
 func (s *CryptoS) IVFromBase64String(data string) *CryptoS {
-	result, err := base64.StdEncoding.DecodeString(data)
-	s.Errors = errors.Join(s.Errors, err)
-	s.IV = result
-	return s
+	return s.IVFromEncodedString(data, codec.Base64Std)
 }
 
 // IVFromHexString set IV data from hex string.
 func (s *CryptoS) IVFromHexString(data string) *CryptoS {
-	result, err := hex.DecodeString(data)
+	return s.IVFromEncodedString(data, codec.Hex)
+}
+
+// IVFromEncodedString set IV data from string encoded by the encoding such as codec.Base64RawURL.
+func (s *CryptoS) IVFromEncodedString(data string, encoding codec.Encoding) *CryptoS {
+	result, err := encoding.DecodeString(data)
 	s.Errors = errors.Join(s.Errors, err)
 	s.IV = result
 	return s
 }
+
+// ivSize returns the IV size required by the method and mode, zero means the IV is not used.
+func (s *CryptoS) ivSize(blockSize int) int {
+	if s.isAEAD() {
+		return s.nonceSize()
+	}
+
+	if s.Mode == mode.ECB {
+		return 0
+	}
+
+	return blockSize
+}
+
+// generateIV set IV data with random bytes generated by crypto/rand.
+func (s *CryptoS) generateIV(size int) error {
+	iv := make([]byte, size)
+	if _, err := rand.Read(iv); err != nil {
+		return fmt.Errorf("failed to generate IV, error:%s", err)
+	}
+
+	s.IV = iv
+	return nil
+}
+
+// splitIV set IV data from the front of the input data, the rest is the ciphertext.
+func (s *CryptoS) splitIV(size int) error {
+	if len(s.InputData) < size {
+		return errors.New("the input data is shorter than the IV")
+	}
+
+	s.IV = s.InputData[:size:size]
+	s.InputData = s.InputData[size:]
+	return nil
+}
+
+// prependIV prepends the random IV to the output data.
+func (s *CryptoS) prependIV() {
+	if !s.RandomIV || len(s.IV) == 0 {
+		return
+	}
+
+	result := make([]byte, 0, len(s.IV)+len(s.OutputData))
+	result = append(result, s.IV...)
+	s.OutputData = append(result, s.OutputData...)
+}
@@ -0,0 +1,309 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kdf
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// KDFType is the password-based key derivation function such as PBKDF2.
+type KDFType uint8
+
+const (
+	// PBKDF2 is the password-based key derivation function 2 defined in RFC 8018, HMAC-SHA256 is used as
+	// the pseudorandom function. It is widely supported but not memory-hard.
+	PBKDF2 KDFType = 1 + iota
+
+	// Scrypt is the memory-hard password-based key derivation function defined in RFC 7914.
+	Scrypt
+
+	// Argon2id is the winner of the Password Hashing Competition defined in RFC 9106, it is resistant to both
+	// side-channel and GPU cracking attacks, and is the recommended choice for new applications.
+	Argon2id
+)
+
+// The recommended cost parameters from OWASP password storage cheat sheet.
+const (
+	DefaultPBKDF2Iterations = 600000
+
+	DefaultScryptN = 1 << 17
+	DefaultScryptR = 8
+	DefaultScryptP = 1
+
+	DefaultArgon2idTime    = 2
+	DefaultArgon2idMemory  = 19 * 1024
+	DefaultArgon2idThreads = 1
+)
+
+// The maximum cost parameters accepted by Validate, they prevent the parameters parsed from untrusted data such as
+// the envelope header from exhausting CPU or memory. The memory of scrypt is 128*N*r bytes, it is limited to 1 GiB.
+const (
+	MaxPBKDF2Iterations = 10000000
+
+	MaxScryptN      = 1 << 20
+	MaxScryptR      = 32
+	MaxScryptP      = 16
+	MaxScryptMemory = 1 << 30
+
+	MaxArgon2idTime   = 100
+	MaxArgon2idMemory = 1 << 20
+)
+
+// DefaultSaltSize is the size of salt generated when salt is not provided.
+const DefaultSaltSize = 16
+
+// ErrInvalidParams is returned when the parameters of the key derivation function are not valid.
+var ErrInvalidParams = errors.New("invalid key derivation parameters")
+
+// Params is the parameters of the key derivation function. It should be recorded with the ciphertext,
+// so that the same key can be derived for decryption.
+type Params struct {
+	// Type is the key derivation function such as PBKDF2.
+	Type KDFType
+
+	// Salt is the random salt, it should be unique for each password.
+	Salt []byte
+
+	// Iterations is the iteration count of PBKDF2.
+	Iterations int
+
+	// N is the CPU/memory cost of scrypt, it must be a power of two greater than 1.
+	N int
+
+	// R is the block size of scrypt.
+	R int
+
+	// P is the parallelization of scrypt.
+	P int
+
+	// Time is the number of passes over the memory of Argon2id.
+	Time uint32
+
+	// Memory is the memory size in KiB of Argon2id.
+	Memory uint32
+
+	// Threads is the number of threads of Argon2id.
+	Threads uint8
+}
+
+// NewPBKDF2 returns the parameters of PBKDF2-HMAC-SHA256 with the iteration count.
+func NewPBKDF2(iterations int) Params {
+	return Params{Type: PBKDF2, Iterations: iterations}
+}
+
+// NewScrypt returns the parameters of scrypt with the CPU/memory cost n, the block size r and the parallelization p.
+func NewScrypt(n, r, p int) Params {
+	return Params{Type: Scrypt, N: n, R: r, P: p}
+}
+
+// NewArgon2id returns the parameters of Argon2id with the time cost, the memory size in KiB and the threads.
+func NewArgon2id(time, memory uint32, threads uint8) Params {
+	return Params{Type: Argon2id, Time: time, Memory: memory, Threads: threads}
+}
+
+// DefaultParams returns the recommended parameters of the key derivation function.
+func DefaultParams(kdfType KDFType) Params {
+	switch kdfType {
+	case PBKDF2:
+		return NewPBKDF2(DefaultPBKDF2Iterations)
+	case Scrypt:
+		return NewScrypt(DefaultScryptN, DefaultScryptR, DefaultScryptP)
+	default:
+		return NewArgon2id(DefaultArgon2idTime, DefaultArgon2idMemory, DefaultArgon2idThreads)
+	}
+}
+
+// WithSalt returns a copy of the parameters with the salt.
+func (p Params) WithSalt(salt []byte) Params {
+	p.Salt = append([]byte(nil), salt...)
+	return p
+}
+
+// WithRandomSalt returns a copy of the parameters with a random salt of DefaultSaltSize.
+func (p Params) WithRandomSalt() (Params, error) {
+	salt := make([]byte, DefaultSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return p, fmt.Errorf("failed to generate salt, error:%s", err)
+	}
+	p.Salt = salt
+	return p, nil
+}
+
+// Validate returns error if the parameters are not valid.
+func (p Params) Validate() error {
+	if len(p.Salt) == 0 {
+		return fmt.Errorf("%w: the salt cannot be empty", ErrInvalidParams)
+	}
+
+	switch p.Type {
+	case PBKDF2:
+		if p.Iterations < 1 {
+			return fmt.Errorf("%w: the iterations of PBKDF2 must be positive", ErrInvalidParams)
+		}
+		if p.Iterations > MaxPBKDF2Iterations {
+			return fmt.Errorf("%w: the iterations of PBKDF2 cannot exceed %d", ErrInvalidParams, MaxPBKDF2Iterations)
+		}
+	case Scrypt:
+		if p.N <= 1 || p.N&(p.N-1) != 0 {
+			return fmt.Errorf("%w: the N of scrypt must be a power of two greater than 1", ErrInvalidParams)
+		}
+		if p.R < 1 || p.P < 1 {
+			return fmt.Errorf("%w: the r and p of scrypt must be positive", ErrInvalidParams)
+		}
+		if p.N > MaxScryptN || p.R > MaxScryptR || p.P > MaxScryptP {
+			return fmt.Errorf("%w: the N, r and p of scrypt cannot exceed %d, %d and %d", ErrInvalidParams,
+				MaxScryptN, MaxScryptR, MaxScryptP)
+		}
+		if 128*p.N*p.R > MaxScryptMemory {
+			return fmt.Errorf("%w: the memory of scrypt cannot exceed %d bytes", ErrInvalidParams, MaxScryptMemory)
+		}
+	case Argon2id:
+		if p.Time < 1 || p.Threads < 1 {
+			return fmt.Errorf("%w: the time and threads of Argon2id must be positive", ErrInvalidParams)
+		}
+		if p.Memory < 8*uint32(p.Threads) {
+			return fmt.Errorf("%w: the memory of Argon2id must be at least 8 KiB per thread", ErrInvalidParams)
+		}
+		if p.Time > MaxArgon2idTime || p.Memory > MaxArgon2idMemory {
+			return fmt.Errorf("%w: the time and memory of Argon2id cannot exceed %d and %d KiB", ErrInvalidParams,
+				MaxArgon2idTime, MaxArgon2idMemory)
+		}
+	default:
+		return fmt.Errorf("%w: the key derivation function is not supported", ErrInvalidParams)
+	}
+
+	return nil
+}
+
+// Derive derives a key of keyLen bytes from the password.
+func (p Params) Derive(password []byte, keyLen int) ([]byte, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	if keyLen < 1 {
+		return nil, fmt.Errorf("%w: the key length must be positive", ErrInvalidParams)
+	}
+
+	switch p.Type {
+	case PBKDF2:
+		return pbkdf2.Key(password, p.Salt, p.Iterations, keyLen, sha256.New), nil
+	case Scrypt:
+		return scrypt.Key(password, p.Salt, p.N, p.R, p.P, keyLen)
+	default:
+		return argon2.IDKey(password, p.Salt, p.Time, p.Memory, p.Threads, uint32(keyLen)), nil
+	}
+}
+
+// String returns the parameters in the PHC string format such as "$pbkdf2-sha256$i=600000$c2FsdA".
+func (p Params) String() string {
+	salt := base64.RawStdEncoding.EncodeToString(p.Salt)
+	switch p.Type {
+	case PBKDF2:
+		return fmt.Sprintf("$pbkdf2-sha256$i=%d$%s", p.Iterations, salt)
+	case Scrypt:
+		return fmt.Sprintf("$scrypt$n=%d,r=%d,p=%d$%s", p.N, p.R, p.P, salt)
+	case Argon2id:
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s", argon2.Version, p.Memory, p.Time, p.Threads, salt)
+	}
+	return ""
+}
+
+// Parse parses the parameters from the PHC string format generated by Params.String.
+// The cost parameters are validated against the maximums such as MaxScryptN.
+func Parse(data string) (Params, error) {
+	parts := strings.Split(data, "$")
+	if len(parts) < 4 || parts[0] != "" {
+		return Params{}, fmt.Errorf("%w: the format is not valid", ErrInvalidParams)
+	}
+
+	var p Params
+	var values map[string]int
+	var err error
+
+	switch parts[1] {
+	case "pbkdf2-sha256":
+		if len(parts) != 4 {
+			return Params{}, fmt.Errorf("%w: the format is not valid", ErrInvalidParams)
+		}
+		if values, err = parseValues(parts[2], "i"); err != nil {
+			return Params{}, err
+		}
+		p = NewPBKDF2(values["i"])
+	case "scrypt":
+		if len(parts) != 4 {
+			return Params{}, fmt.Errorf("%w: the format is not valid", ErrInvalidParams)
+		}
+		if values, err = parseValues(parts[2], "n", "r", "p"); err != nil {
+			return Params{}, err
+		}
+		p = NewScrypt(values["n"], values["r"], values["p"])
+	case "argon2id":
+		if len(parts) != 5 || parts[2] != "v="+strconv.Itoa(argon2.Version) {
+			return Params{}, fmt.Errorf("%w: the format is not valid", ErrInvalidParams)
+		}
+		if values, err = parseValues(parts[3], "m", "t", "p"); err != nil {
+			return Params{}, err
+		}
+		if values["p"] > 255 {
+			return Params{}, fmt.Errorf("%w: the threads of Argon2id is too large", ErrInvalidParams)
+		}
+		p = NewArgon2id(uint32(values["t"]), uint32(values["m"]), uint8(values["p"]))
+	default:
+		return Params{}, fmt.Errorf("%w: the key derivation function %q is not supported", ErrInvalidParams, parts[1])
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[len(parts)-1])
+	if err != nil {
+		return Params{}, fmt.Errorf("%w: the salt is not valid", ErrInvalidParams)
+	}
+	p.Salt = salt
+
+	return p, p.Validate()
+}
+
+// parseValues parses the comma separated values such as "n=32768,r=8,p=1" with the keys in order.
+func parseValues(data string, keys ...string) (map[string]int, error) {
+	items := strings.Split(data, ",")
+	if len(items) != len(keys) {
+		return nil, fmt.Errorf("%w: the format is not valid", ErrInvalidParams)
+	}
+
+	result := make(map[string]int, len(keys))
+	for i, item := range items {
+		key, value, ok := strings.Cut(item, "=")
+		if !ok || key != keys[i] {
+			return nil, fmt.Errorf("%w: the format is not valid", ErrInvalidParams)
+		}
+
+		number, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%w: the value of %s is not valid", ErrInvalidParams, key)
+		}
+		result[key] = int(number)
+	}
+
+	return result, nil
+}
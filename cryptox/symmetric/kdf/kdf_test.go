@@ -0,0 +1,142 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kdf
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParams_Derive(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   Params
+		password string
+		salt     string
+		keyLen   int
+		want     string
+	}{
+		{
+			name:     "PBKDF2-HMAC-SHA256",
+			params:   NewPBKDF2(1),
+			password: "password",
+			salt:     "salt",
+			keyLen:   32,
+			want:     "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b",
+		},
+		{
+			name:     "scrypt RFC 7914",
+			params:   NewScrypt(1024, 8, 16),
+			password: "password",
+			salt:     "NaCl",
+			keyLen:   64,
+			want: "fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b373162" +
+				"2eaf30d92e22a3886ff109279d9830dac727afb94a83ee6d8360cbdfa2cc0640",
+		},
+		{
+			name:     "Argon2id",
+			params:   NewArgon2id(1, 64, 1),
+			password: "password",
+			salt:     "somesalt",
+			keyLen:   24,
+			want:     "655ad15eac652dc59f7170a7332bf49b8469be1fdb9c28bb",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.params.WithSalt([]byte(tt.salt)).Derive([]byte(tt.password), tt.keyLen)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, hex.EncodeToString(got))
+		})
+	}
+}
+
+func TestParams_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		params Params
+	}{
+		{name: "empty salt", params: NewPBKDF2(1)},
+		{name: "zero iterations", params: NewPBKDF2(0).WithSalt([]byte("salt"))},
+		{name: "N is not power of two", params: NewScrypt(1000, 8, 1).WithSalt([]byte("salt"))},
+		{name: "zero r", params: NewScrypt(1024, 0, 1).WithSalt([]byte("salt"))},
+		{name: "zero threads", params: NewArgon2id(1, 64, 0).WithSalt([]byte("salt"))},
+		{name: "small memory", params: NewArgon2id(1, 8, 2).WithSalt([]byte("salt"))},
+		{name: "too many iterations", params: NewPBKDF2(MaxPBKDF2Iterations + 1).WithSalt([]byte("salt"))},
+		{name: "too large N", params: NewScrypt(MaxScryptN<<1, 1, 1).WithSalt([]byte("salt"))},
+		{name: "too large r", params: NewScrypt(1024, MaxScryptR+1, 1).WithSalt([]byte("salt"))},
+		{name: "too large p", params: NewScrypt(1024, 8, MaxScryptP+1).WithSalt([]byte("salt"))},
+		{name: "too large scrypt memory", params: NewScrypt(MaxScryptN, MaxScryptR, 1).WithSalt([]byte("salt"))},
+		{name: "too large time", params: NewArgon2id(MaxArgon2idTime+1, 64, 1).WithSalt([]byte("salt"))},
+		{name: "too large memory", params: NewArgon2id(1, MaxArgon2idMemory+1, 1).WithSalt([]byte("salt"))},
+		{name: "unknown type", params: Params{Salt: []byte("salt")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, errors.Is(tt.params.Validate(), ErrInvalidParams))
+
+			_, err := tt.params.Derive([]byte("password"), 32)
+			assert.True(t, errors.Is(err, ErrInvalidParams))
+		})
+	}
+
+	_, err := NewPBKDF2(1).WithSalt([]byte("salt")).Derive([]byte("password"), 0)
+	assert.True(t, errors.Is(err, ErrInvalidParams))
+}
+
+func TestParams_String(t *testing.T) {
+	for _, kdfType := range []KDFType{PBKDF2, Scrypt, Argon2id} {
+		params, err := DefaultParams(kdfType).WithRandomSalt()
+		assert.Nil(t, err)
+		assert.Equal(t, DefaultSaltSize, len(params.Salt))
+
+		result, err := Parse(params.String())
+		assert.Nil(t, err)
+		assert.Equal(t, params, result)
+	}
+
+	assert.Equal(t, "$pbkdf2-sha256$i=1000$c2FsdA", NewPBKDF2(1000).WithSalt([]byte("salt")).String())
+	assert.Equal(t, "$scrypt$n=1024,r=8,p=1$c2FsdA", NewScrypt(1024, 8, 1).WithSalt([]byte("salt")).String())
+	assert.Equal(t, "$argon2id$v=19$m=64,t=1,p=1$c2FsdA", NewArgon2id(1, 64, 1).WithSalt([]byte("salt")).String())
+	assert.Equal(t, "", Params{}.String())
+}
+
+func TestParse(t *testing.T) {
+	for _, data := range []string{
+		"",
+		"pbkdf2-sha256$i=1000$c2FsdA",
+		"$pbkdf2-sha256$i=1000$c2FsdA$",
+		"$pbkdf2-sha256$n=1000$c2FsdA",
+		"$pbkdf2-sha256$i=abc$c2FsdA",
+		"$pbkdf2-sha256$i=1000$!",
+		"$pbkdf2-sha256$i=0$c2FsdA",
+		"$scrypt$n=1024,r=8$c2FsdA",
+		"$scrypt$n=1024,r=8,p=1$c2FsdA$",
+		"$argon2id$v=16$m=64,t=1,p=1$c2FsdA",
+		"$argon2id$v=19$m=64,t=1,p=256$c2FsdA",
+		"$bcrypt$c=10$c2FsdA",
+		"$pbkdf2-sha256$i=4294967295$c2FsdA",
+		"$scrypt$n=2147483648,r=8,p=1$c2FsdA",
+		"$scrypt$n=1024,r=4294967295,p=1$c2FsdA",
+		"$argon2id$v=19$m=4294967295,t=1,p=1$c2FsdA",
+		"$argon2id$v=19$m=64,t=4294967295,p=1$c2FsdA",
+	} {
+		_, err := Parse(data)
+		assert.True(t, errors.Is(err, ErrInvalidParams), data)
+	}
+}
@@ -15,9 +15,12 @@
 package symmetric
 
 import (
-	"encoding/base64"
-	"encoding/hex"
 	"errors"
+	"fmt"
+
+	"github.com/suyuan32/knife/core/codec"
+	"github.com/suyuan32/knife/cryptox/symmetric/kdf"
+	"github.com/suyuan32/knife/cryptox/symmetric/method"
 )
 
 // KeyFromBytes set key data from byte slice.
@@ -34,16 +37,81 @@ func (s *CryptoS) KeyFromString(data string) *CryptoS {
 
 // KeyFromBase64String set key data from base64 string.
 func (s *CryptoS) KeyFromBase64String(data string) *CryptoS {
-	result, err := base64.StdEncoding.DecodeString(data)
-	s.Errors = errors.Join(s.Errors, err)
-	s.Key = result
-	return s
+	return s.KeyFromEncodedString(data, codec.Base64Std)
 }
 
 // KeyFromHexString set key data from hex string.
 func (s *CryptoS) KeyFromHexString(data string) *CryptoS {
-	result, err := hex.DecodeString(data)
+	return s.KeyFromEncodedString(data, codec.Hex)
+}
+
+// KeyFromEncodedString set key data from string encoded by the encoding such as codec.Base58.
+func (s *CryptoS) KeyFromEncodedString(data string, encoding codec.Encoding) *CryptoS {
+	result, err := encoding.DecodeString(data)
 	s.Errors = errors.Join(s.Errors, err)
 	s.Key = result
 	return s
 }
+
+// KeyFromPassword set key data derived from the password by the key derivation function such as Argon2id.
+// A random salt is generated if salt is empty. The key size depends on the method, such as 32 bytes for AES.
+// The salt and parameters are recorded in KDF and the envelope, so that DeriveKey can derive the same key.
+func (s *CryptoS) KeyFromPassword(password string, salt []byte, params kdf.Params) *CryptoS {
+	if len(salt) == 0 {
+		var err error
+		if params, err = params.WithRandomSalt(); err != nil {
+			s.Errors = errors.Join(s.Errors, err)
+			return s
+		}
+	} else {
+		params = params.WithSalt(salt)
+	}
+
+	s.KDF = &params
+
+	return s.DeriveKey(password)
+}
+
+// DeriveKey set key data derived from the password with the parameters recorded in KDF,
+// which are usually read from the envelope.
+func (s *CryptoS) DeriveKey(password string) *CryptoS {
+	if s.KDF == nil {
+		s.Errors = errors.Join(s.Errors, errors.New("the key derivation parameters cannot be empty"))
+		return s
+	}
+
+	result, err := s.KDF.Derive([]byte(password), s.keySize())
+	if err != nil {
+		s.Errors = errors.Join(s.Errors, fmt.Errorf("failed to derive key, error:%s", err))
+		return s
+	}
+
+	s.Key = result
+	return s
+}
+
+// key returns the key of KeyHandle if it is set, otherwise Key. The key of the handle is only available in an
+// operation and must not be retained after it, it returns nil if the handle has been destroyed.
+func (s *CryptoS) key() []byte {
+	if s.KeyHandle == nil {
+		return s.Key
+	}
+
+	if s.op == nil {
+		return nil
+	}
+	return s.op.key
+}
+
+// keySize returns the key size used by the method when the key is derived.
+func (s *CryptoS) keySize() int {
+	switch s.Method {
+	case method.AES, method.Twofish, method.ChaCha20Poly1305, method.XChaCha20Poly1305:
+		return 32
+	case method.DES:
+		return 8
+	case method.TripleDES:
+		return 24
+	}
+	return 16
+}
@@ -0,0 +1,221 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrKeyNotFound is returned when the key ID cannot be found in the keyring.
+var ErrKeyNotFound = errors.New("key not found")
+
+// Keyring holds versioned keys identified by key IDs, one of them is the primary key used for encryption.
+// The other keys are kept to decrypt the old ciphertexts until they are re-encrypted by ReEncrypt.
+// It is safe to be used concurrently by multiple goroutines.
+type Keyring struct {
+	mu      sync.RWMutex
+	keys    map[string][]byte
+	primary string
+}
+
+// NewKeyring returns an empty keyring.
+func NewKeyring() *Keyring {
+	return &Keyring{keys: map[string][]byte{}}
+}
+
+// Add adds a key with the key ID to the keyring, the key is copied. The first key added becomes the primary key.
+func (k *Keyring) Add(id string, key []byte) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	return k.add(id, key)
+}
+
+// Rotate adds a new key with the key ID and makes it the primary key, the old keys are kept for decryption.
+func (k *Keyring) Rotate(id string, key []byte) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if err := k.add(id, key); err != nil {
+		return err
+	}
+
+	k.primary = id
+	return nil
+}
+
+// add adds a key to the keyring, the lock must be held by the caller.
+func (k *Keyring) add(id string, key []byte) error {
+	if id == "" {
+		return errors.New("the key ID cannot be empty")
+	}
+
+	// the key ID is recorded in an envelope field
+	if len(id) > 0xffff {
+		return errors.New("the key ID is too long")
+	}
+
+	if len(key) == 0 {
+		return errors.New("the key cannot be empty")
+	}
+
+	if _, ok := k.keys[id]; ok {
+		return fmt.Errorf("the key %s already exists", id)
+	}
+
+	k.keys[id] = bytes.Clone(key)
+	if k.primary == "" {
+		k.primary = id
+	}
+
+	return nil
+}
+
+// SetPrimary makes the key with the key ID the primary key.
+func (k *Keyring) SetPrimary(id string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if _, ok := k.keys[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrKeyNotFound, id)
+	}
+
+	k.primary = id
+	return nil
+}
+
+// Remove removes the key with the key ID, the primary key cannot be removed.
+func (k *Keyring) Remove(id string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if _, ok := k.keys[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrKeyNotFound, id)
+	}
+
+	if id == k.primary {
+		return fmt.Errorf("the primary key %s cannot be removed", id)
+	}
+
+	delete(k.keys, id)
+	return nil
+}
+
+// Primary returns the key ID and a copy of the primary key.
+func (k *Keyring) Primary() (string, []byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if k.primary == "" {
+		return "", nil, errors.New("the keyring is empty")
+	}
+
+	return k.primary, bytes.Clone(k.keys[k.primary]), nil
+}
+
+// Key returns a copy of the key with the key ID.
+func (k *Keyring) Key(id string) ([]byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, id)
+	}
+
+	return bytes.Clone(key), nil
+}
+
+// IDs returns the sorted key IDs in the keyring.
+func (k *Keyring) IDs() []string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	ids := make([]string, 0, len(k.keys))
+	for id := range k.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
+
+// keyFromKeyring set the key from the keyring, the primary key and its ID are used for encryption,
+// and the key is found by KeyID for decryption.
+func (s *CryptoS) keyFromKeyring(encrypt bool) error {
+	// the key of the handle would be used with the key ID of the keyring
+	if s.KeyHandle != nil {
+		return newValidationError("key", "the key handle cannot be used with the keyring")
+	}
+
+	if encrypt {
+		id, key, err := s.Keyring.Primary()
+		if err != nil {
+			return err
+		}
+
+		s.KeyID, s.Key = id, key
+		return nil
+	}
+
+	if s.KeyID == "" {
+		return errors.New("the key ID is required to find the key in the keyring")
+	}
+
+	key, err := s.Keyring.Key(s.KeyID)
+	if err != nil {
+		return err
+	}
+
+	s.Key = key
+	return nil
+}
+
+// ReEncrypt decrypts the envelope with the key found in the keyring by its key ID, then encrypts the plaintext with
+// the primary key and the configuration of CryptoS, and returns the new envelope.
+// A random IV is always generated for the new ciphertext. It is used to migrate the old ciphertexts after rotation.
+func (s *CryptoS) ReEncrypt(envelope []byte) ([]byte, error) {
+	if s.Keyring == nil {
+		return nil, errors.New("the keyring cannot be empty")
+	}
+
+	d := NewCryptoS()
+	d.Keyring = s.Keyring
+	d.AdditionalData = s.AdditionalData
+	d.ECBAllowed = s.ECBAllowed
+	d.InsecureAllowed = s.InsecureAllowed
+	if d.FromEnvelope(envelope).Decrypt(); d.Errors != nil {
+		return nil, fmt.Errorf("failed to decrypt the envelope, error:%w", d.Errors)
+	}
+
+	e := *s
+	e.InputData = d.OutputData
+	e.OutputData = nil
+	e.Key = nil
+	e.IV = nil
+	e.KeyID = ""
+	e.KDF = nil
+	e.RandomIV = true
+	e.Errors = nil
+	if e.Encrypt(); e.Errors != nil {
+		return nil, fmt.Errorf("failed to encrypt with the primary key, error:%w", e.Errors)
+	}
+
+	return e.ToEnvelope()
+}
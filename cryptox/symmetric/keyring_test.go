@@ -0,0 +1,176 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/suyuan32/knife/cryptox/keyhandle"
+	"github.com/suyuan32/knife/cryptox/symmetric/method"
+	"github.com/suyuan32/knife/cryptox/symmetric/mode"
+	"github.com/suyuan32/knife/cryptox/symmetric/padding"
+)
+
+func TestKeyring(t *testing.T) {
+	k := NewKeyring()
+
+	_, _, err := k.Primary()
+	assert.NotNil(t, err)
+
+	key := bytes.Repeat([]byte{'a'}, 32)
+	assert.Nil(t, k.Add("2023Q1", key))
+	assert.NotNil(t, k.Add("2023Q1", key))
+	assert.NotNil(t, k.Add("", key))
+	assert.NotNil(t, k.Add("empty", nil))
+
+	// the key is copied
+	key[0] = 'b'
+	id, primary, err := k.Primary()
+	assert.Nil(t, err)
+	assert.Equal(t, "2023Q1", id)
+	assert.Equal(t, bytes.Repeat([]byte{'a'}, 32), primary)
+
+	assert.Nil(t, k.Rotate("2023Q2", bytes.Repeat([]byte{'b'}, 32)))
+	id, _, _ = k.Primary()
+	assert.Equal(t, "2023Q2", id)
+	assert.Equal(t, []string{"2023Q1", "2023Q2"}, k.IDs())
+
+	_, err = k.Key("2023Q3")
+	assert.True(t, errors.Is(err, ErrKeyNotFound))
+	assert.True(t, errors.Is(k.SetPrimary("2023Q3"), ErrKeyNotFound))
+	assert.True(t, errors.Is(k.Remove("2023Q3"), ErrKeyNotFound))
+	assert.NotNil(t, k.Remove("2023Q2"))
+
+	assert.Nil(t, k.SetPrimary("2023Q1"))
+	assert.Nil(t, k.Remove("2023Q2"))
+	assert.Equal(t, []string{"2023Q1"}, k.IDs())
+}
+
+func TestCryptoS_Keyring(t *testing.T) {
+	testStr := []byte("keyring data")
+	k := NewKeyring()
+	assert.Nil(t, k.Add("v1", bytes.Repeat([]byte{'a'}, 32)))
+
+	tests := []struct {
+		method  method.MethodType
+		mode    mode.ModeType
+		padding padding.PaddingType
+	}{
+		{method: method.AES, mode: mode.CBC, padding: padding.PKCS7},
+		{method: method.AES, mode: mode.GCM},
+		{method: method.ChaCha20Poly1305},
+	}
+
+	for _, v := range tests {
+		c := newTestCryptoS()
+		c.WithMethod(v.method).WithMode(v.mode).WithPadding(v.padding).WithRandomIV().WithKeyring(k).
+			InputFromBytes(testStr).Encrypt()
+		assert.Nil(t, c.Errors)
+		assert.Equal(t, "v1", c.KeyID)
+
+		envelope, err := c.ToEnvelope()
+		assert.Nil(t, err)
+
+		c = newTestCryptoS()
+		result, err := c.WithKeyring(k).FromEnvelope(envelope).Decrypt().ToBytes()
+		assert.Nil(t, err)
+		assert.Equal(t, testStr, result)
+	}
+
+	// the key ID is required for decryption
+	c := newTestCryptoS()
+	c.WithKeyring(k).WithPadding(padding.PKCS7).WithRandomIV().InputFromBytes(bytes.Repeat([]byte{'a'}, 32)).Decrypt()
+	assert.NotNil(t, c.Errors)
+
+	c = newTestCryptoS()
+	c.WithKeyring(k).WithKeyID("v0").WithPadding(padding.PKCS7).WithRandomIV().
+		InputFromBytes(bytes.Repeat([]byte{'a'}, 32)).Decrypt()
+	assert.True(t, errors.Is(c.Errors, ErrKeyNotFound))
+
+	_, err := c.Build()
+	assert.NotNil(t, err)
+
+	// the key handle cannot be mixed with the key ID of the keyring
+	handle, err := keyhandle.Seal(bytes.Repeat([]byte{'b'}, 32))
+	assert.Nil(t, err)
+	for _, encrypt := range []bool{true, false} {
+		c = newTestCryptoS()
+		c.WithKeyring(k).WithKeyID("v1").WithKeyHandle(handle).WithPadding(padding.PKCS7).
+			InputFromBytes(bytes.Repeat([]byte{'a'}, 32))
+		if encrypt {
+			c.Encrypt()
+		} else {
+			c.Decrypt()
+		}
+		var validationErr *ValidationError
+		assert.True(t, errors.As(c.Errors, &validationErr))
+		assert.Equal(t, "key", validationErr.Field)
+	}
+}
+
+func TestCryptoS_ReEncrypt(t *testing.T) {
+	testStr := []byte("data encrypted by the old key")
+	k := NewKeyring()
+	assert.Nil(t, k.Add("v1", bytes.Repeat([]byte{'a'}, 16)))
+
+	c := newTestCryptoS()
+	c.WithMethod(method.SM4).WithPadding(padding.PKCS7).WithRandomIV().WithKeyring(k).
+		WithAdditionalData([]byte("aad")).InputFromBytes(testStr).Encrypt()
+	old, err := c.ToEnvelope()
+	assert.Nil(t, err)
+
+	assert.Nil(t, k.Rotate("v2", bytes.Repeat([]byte{'b'}, 32)))
+
+	// migrates from SM4-CBC to AES-GCM with the new key
+	c = newTestCryptoS()
+	c.WithMethod(method.AES).WithMode(mode.GCM).WithKeyring(k).WithAdditionalData([]byte("aad"))
+	var wg sync.WaitGroup
+	results := make([][]byte, 4)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = c.ReEncrypt(old)
+		}(i)
+	}
+	wg.Wait()
+	assert.NotEqual(t, results[0], results[1])
+
+	for _, v := range results {
+		d := newTestCryptoS()
+		result, err := d.WithKeyring(k).WithAdditionalData([]byte("aad")).FromEnvelope(v).Decrypt().ToBytes()
+		assert.Nil(t, err)
+		assert.Equal(t, testStr, result)
+		assert.Equal(t, "v2", d.KeyID)
+		assert.Equal(t, mode.GCM, d.Mode)
+	}
+
+	// the old key is no longer needed
+	assert.Nil(t, k.Remove("v1"))
+	d := newTestCryptoS()
+	d.WithKeyring(k).WithAdditionalData([]byte("aad")).FromEnvelope(old).Decrypt()
+	assert.True(t, errors.Is(d.Errors, ErrKeyNotFound))
+
+	_, err = c.ReEncrypt(old)
+	assert.True(t, errors.Is(err, ErrKeyNotFound))
+
+	_, err = newTestCryptoS().ReEncrypt(old)
+	assert.NotNil(t, err)
+}
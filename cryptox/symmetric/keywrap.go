@@ -0,0 +1,175 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+// keyWrapBlockSize is the block size required by the key wrap.
+const keyWrapBlockSize = 16
+
+// keyWrapIV is the default initial value defined in RFC 3394.
+var keyWrapIV = []byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+
+// keyWrapPaddingIV is the prefix of the alternative initial value defined in RFC 5649.
+var keyWrapPaddingIV = []byte{0xa6, 0x59, 0x59, 0xa6}
+
+// KeyWrap wraps the key with the key encryption key block by the key wrap algorithm defined in RFC 3394,
+// such as AES-KW. The block must be a 128-bit block cipher such as AES or SM4 returned by CryptoS.NewCipher,
+// and the key size must be a multiple of 8 and at least 16 bytes.
+func KeyWrap(block cipher.Block, key []byte) ([]byte, error) {
+	if block.BlockSize() != keyWrapBlockSize {
+		return nil, errors.New("the key wrap requires a 128-bit block cipher")
+	}
+
+	if len(key) < 16 || len(key)%8 != 0 {
+		return nil, errors.New("the key size must be a multiple of 8 and at least 16 bytes")
+	}
+
+	return wrap(block, keyWrapIV, key), nil
+}
+
+// KeyUnwrap unwraps the key wrapped by KeyWrap. ErrAuthenticationFailed is returned if the integrity check fails.
+func KeyUnwrap(block cipher.Block, wrapped []byte) ([]byte, error) {
+	if block.BlockSize() != keyWrapBlockSize {
+		return nil, errors.New("the key wrap requires a 128-bit block cipher")
+	}
+
+	if len(wrapped) < 24 || len(wrapped)%8 != 0 {
+		return nil, errors.New("the wrapped key size must be a multiple of 8 and at least 24 bytes")
+	}
+
+	a, key := unwrap(block, wrapped)
+	if subtle.ConstantTimeCompare(a, keyWrapIV) != 1 {
+		return nil, ErrAuthenticationFailed
+	}
+
+	return key, nil
+}
+
+// KeyWrapWithPadding wraps the key of any size with the key encryption key block by the key wrap with padding
+// algorithm defined in RFC 5649, such as AES-KWP. The block must be a 128-bit block cipher.
+func KeyWrapWithPadding(block cipher.Block, key []byte) ([]byte, error) {
+	if block.BlockSize() != keyWrapBlockSize {
+		return nil, errors.New("the key wrap requires a 128-bit block cipher")
+	}
+
+	if len(key) == 0 || uint64(len(key)) > 0xffffffff {
+		return nil, errors.New("the key size must be between 1 and 2^32-1 bytes")
+	}
+
+	iv := make([]byte, 8)
+	copy(iv, keyWrapPaddingIV)
+	binary.BigEndian.PutUint32(iv[4:], uint32(len(key)))
+
+	padded := make([]byte, (len(key)+7)/8*8)
+	copy(padded, key)
+
+	if len(padded) == 8 {
+		result := make([]byte, keyWrapBlockSize)
+		block.Encrypt(result, append(iv, padded...))
+		return result, nil
+	}
+
+	return wrap(block, iv, padded), nil
+}
+
+// KeyUnwrapWithPadding unwraps the key wrapped by KeyWrapWithPadding. ErrAuthenticationFailed is returned if
+// the integrity check fails.
+func KeyUnwrapWithPadding(block cipher.Block, wrapped []byte) ([]byte, error) {
+	if block.BlockSize() != keyWrapBlockSize {
+		return nil, errors.New("the key wrap requires a 128-bit block cipher")
+	}
+
+	if len(wrapped) < 16 || len(wrapped)%8 != 0 {
+		return nil, errors.New("the wrapped key size must be a multiple of 8 and at least 16 bytes")
+	}
+
+	var a, padded []byte
+	if len(wrapped) == keyWrapBlockSize {
+		result := make([]byte, keyWrapBlockSize)
+		block.Decrypt(result, wrapped)
+		a, padded = result[:8], result[8:]
+	} else {
+		a, padded = unwrap(block, wrapped)
+	}
+
+	size := uint64(binary.BigEndian.Uint32(a[4:]))
+	if subtle.ConstantTimeCompare(a[:4], keyWrapPaddingIV) != 1 ||
+		size+8 <= uint64(len(padded)) || size > uint64(len(padded)) {
+		return nil, ErrAuthenticationFailed
+	}
+
+	var nonZero byte
+	for _, v := range padded[size:] {
+		nonZero |= v
+	}
+	if nonZero != 0 {
+		return nil, ErrAuthenticationFailed
+	}
+
+	return padded[:size], nil
+}
+
+// wrap wraps the 64-bit blocks of data with the initial value.
+func wrap(block cipher.Block, iv, data []byte) []byte {
+	n := len(data) / 8
+	result := make([]byte, 8+len(data))
+	copy(result, iv)
+	copy(result[8:], data)
+
+	b := make([]byte, keyWrapBlockSize)
+	for j := 0; j < 6; j++ {
+		for i := 1; i <= n; i++ {
+			copy(b, result[:8])
+			copy(b[8:], result[8*i:8*i+8])
+			block.Encrypt(b, b)
+
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(result[:8], binary.BigEndian.Uint64(b[:8])^t)
+			copy(result[8*i:8*i+8], b[8:])
+		}
+	}
+
+	return result
+}
+
+// unwrap unwraps the data and returns the initial value and the 64-bit blocks.
+func unwrap(block cipher.Block, data []byte) ([]byte, []byte) {
+	n := len(data)/8 - 1
+	a := make([]byte, 8)
+	copy(a, data[:8])
+	r := make([]byte, len(data)-8)
+	copy(r, data[8:])
+
+	b := make([]byte, keyWrapBlockSize)
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(b[:8], binary.BigEndian.Uint64(a)^t)
+			copy(b[8:], r[8*(i-1):8*i])
+			block.Decrypt(b, b)
+
+			copy(a, b[:8])
+			copy(r[8*(i-1):8*i], b[8:])
+		}
+	}
+
+	return a, r
+}
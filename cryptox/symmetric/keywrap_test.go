@@ -0,0 +1,149 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/suyuan32/knife/cryptox/symmetric/method"
+)
+
+func TestKeyWrap(t *testing.T) {
+	tests := []struct {
+		name string
+		kek  string
+		key  string
+		want string
+	}{
+		{
+			name: "RFC 3394 4.1 128-bit KEK and key",
+			kek:  "000102030405060708090a0b0c0d0e0f",
+			key:  "00112233445566778899aabbccddeeff",
+			want: "1fa68b0a8112b447aef34bd8fb5a7b829d3e862371d2cfe5",
+		},
+		{
+			name: "RFC 3394 4.6 256-bit KEK and key",
+			kek:  "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+			key:  "00112233445566778899aabbccddeeff000102030405060708090a0b0c0d0e0f",
+			want: "28c9f404c4b810f4cbccb35cfb87f8263f5786e2d80ed326cbc7f0e71a99f43bfb988b9b7a02dd21",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block, err := aes.NewCipher(mustDecodeHex(tt.kek))
+			assert.Nil(t, err)
+
+			wrapped, err := KeyWrap(block, mustDecodeHex(tt.key))
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, hex.EncodeToString(wrapped))
+
+			key, err := KeyUnwrap(block, wrapped)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.key, hex.EncodeToString(key))
+
+			wrapped[0] ^= 1
+			_, err = KeyUnwrap(block, wrapped)
+			assert.True(t, errors.Is(err, ErrAuthenticationFailed))
+		})
+	}
+
+	block, _ := aes.NewCipher(make([]byte, 16))
+	_, err := KeyWrap(block, make([]byte, 12))
+	assert.NotNil(t, err)
+	_, err = KeyUnwrap(block, make([]byte, 16))
+	assert.NotNil(t, err)
+}
+
+func TestKeyWrapWithPadding(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		want string
+	}{
+		{
+			name: "RFC 5649 20 bytes key",
+			key:  "c37b7e6492584340bed12207808941155068f738",
+			want: "138bdeaa9b8fa7fc61f97742e72248ee5ae6ae5360d1ae6a5f54f373fa543b6a",
+		},
+		{
+			name: "RFC 5649 7 bytes key",
+			key:  "466f7250617369",
+			want: "afbeb0f07dfbf5419200f2ccb50bb24f",
+		},
+	}
+	block, err := aes.NewCipher(mustDecodeHex("5840df6e29b02af1ab493b705bf16ea1ae8338f4dcc176a8"))
+	assert.Nil(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped, err := KeyWrapWithPadding(block, mustDecodeHex(tt.key))
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, hex.EncodeToString(wrapped))
+
+			key, err := KeyUnwrapWithPadding(block, wrapped)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.key, hex.EncodeToString(key))
+
+			wrapped[len(wrapped)-1] ^= 1
+			_, err = KeyUnwrapWithPadding(block, wrapped)
+			assert.True(t, errors.Is(err, ErrAuthenticationFailed))
+		})
+	}
+
+	// the key wrapped without padding cannot be unwrapped with padding
+	wrapped, err := KeyWrap(block, bytes.Repeat([]byte{1}, 16))
+	assert.Nil(t, err)
+	_, err = KeyUnwrapWithPadding(block, wrapped)
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed))
+}
+
+func TestKeyWrap_SM4(t *testing.T) {
+	c := NewCryptoS()
+	block, err := c.WithMethod(method.SM4).KeyFromString("1234567890123456").NewCipher()
+	assert.Nil(t, err)
+
+	for _, size := range []int{1, 8, 16, 20, 32} {
+		key := bytes.Repeat([]byte{'k'}, size)
+
+		wrapped, err := KeyWrapWithPadding(block, key)
+		assert.Nil(t, err)
+
+		result, err := KeyUnwrapWithPadding(block, wrapped)
+		assert.Nil(t, err)
+		assert.Equal(t, key, result)
+
+		if size >= 16 && size%8 == 0 {
+			wrapped, err = KeyWrap(block, key)
+			assert.Nil(t, err)
+
+			result, err = KeyUnwrap(block, wrapped)
+			assert.Nil(t, err)
+			assert.Equal(t, key, result)
+		}
+	}
+
+	// 64-bit block ciphers are not supported
+	c = NewCryptoS()
+	block, err = c.WithMethod(method.TEA).KeyFromString("1234567890123456").NewCipher()
+	assert.Nil(t, err)
+	_, err = KeyWrap(block, make([]byte, 16))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,70 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mac
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/suyuan32/knife/cryptox/hash/sm3"
+)
+
+// MACType is the message authentication code used by encrypt-then-MAC such as HMAC-SHA256.
+type MACType uint8
+
+const (
+	// HMACSHA256 is the HMAC defined in RFC 2104 with SHA-256, its tag size is 32 bytes.
+	HMACSHA256 MACType = 1 + iota
+
+	// HMACSM3 is the HMAC with the SM3 hash algorithm, official standard in China, its tag size is 32 bytes.
+	HMACSM3
+)
+
+// macNames is the names of the MACs.
+var macNames = map[MACType]string{
+	HMACSHA256: "HMAC-SHA256",
+	HMACSM3:    "HMAC-SM3",
+}
+
+// String returns the name of the MAC such as HMAC-SHA256.
+func (m MACType) String() string {
+	if name, ok := macNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("MACType(%d)", m)
+}
+
+// HashFunc returns the hash function used by the MAC.
+func HashFunc(macType MACType) (func() hash.Hash, error) {
+	switch macType {
+	case HMACSHA256:
+		return sha256.New, nil
+	case HMACSM3:
+		return sm3.New, nil
+	}
+	return nil, errors.New("the MAC is not supported")
+}
+
+// New returns a new MAC hash with the key.
+func New(macType MACType, key []byte) (hash.Hash, error) {
+	h, err := HashFunc(macType)
+	if err != nil {
+		return nil, err
+	}
+	return hmac.New(h, key), nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mac
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		macType MACType
+		key     string
+		data    string
+		want    string
+	}{
+		{
+			name:    "HMAC-SHA256 RFC 4231 test case 2",
+			macType: HMACSHA256,
+			key:     "Jefe",
+			data:    "what do ya want for nothing?",
+			want:    "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843",
+		},
+		{
+			name:    "HMAC-SM3",
+			macType: HMACSM3,
+			key:     "Jefe",
+			data:    "what do ya want for nothing?",
+			want:    "2e87f1d16862e6d964b50a5200bf2b10b764faa9680a296a2405f24bec39f882",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := New(tt.macType, []byte(tt.key))
+			assert.Nil(t, err)
+
+			_, _ = h.Write([]byte(tt.data))
+			assert.Equal(t, tt.want, hex.EncodeToString(h.Sum(nil)))
+		})
+	}
+
+	_, err := New(0, []byte("key"))
+	assert.NotNil(t, err)
+}
@@ -14,22 +14,19 @@
 
 package method
 
+import "fmt"
+
 // MethodType is the encryption method such as AES.
 type MethodType uint8
 
-// Only secure algorithms are provided.
+// Secure algorithms are provided by default. The insecure algorithms DES, TripleDES and Blowfish are only provided
+// to decrypt payloads of legacy systems, CryptoS requires AllowInsecure to be called before using them.
 const (
 	// The AES Encryption algorithm (also known as the Rijndael algorithm) is a symmetric block cipher algorithm with
 	// a block/chunk size of 128 bits. It converts these individual blocks using keys of 128, 192, and 256 bits.
 	// Once it encrypts these blocks, it joins them together to form the ciphertext.
 	AES MethodType = 1 + iota
 
-	// The ChaCha20 Encryption Algorithm ChaCha20 is a stream cipher designed by D. J. Bernstein.
-	// It is a refinement of the Salsa20 algorithm, and it uses a 256-bit key.
-	// ChaCha20 successively calls the ChaCha20 block function, with the same key and nonce, and with successively
-	// increasing block counter parameters.
-	//ChaCha20
-
 	// CAST5 is a symmetric block cipher with a block-size of 8 bytes and a variable key-size of up to 128 bits.
 	// Its authors and their employer (Entrust Technologies, a Nortel majority-owned company), made it available worldwide
 	// on a royalty-free basis for commercial and non-commercial uses.
@@ -52,4 +49,57 @@ const (
 	// Several differences from TEA are apparent, including a somewhat more complex key-schedule and a
 	// rearrangement of the shifts, XORs, and additions.
 	XTEA
+
+	// ChaCha20Poly1305 is the AEAD construction of RFC 8439. ChaCha20 is a stream cipher designed by D. J. Bernstein,
+	// it is a refinement of the Salsa20 algorithm and uses a 256-bit key, the Poly1305 authenticator is used to
+	// authenticate the ciphertext and associated data. The nonce size is 96 bits. It is fast on devices without
+	// AES instructions. It is an AEAD method, so the mode is ignored.
+	ChaCha20Poly1305
+
+	// XChaCha20Poly1305 is the ChaCha20-Poly1305 variant with an extended 192-bit nonce, which is large enough to
+	// be generated randomly without the risk of collisions. It is an AEAD method, so the mode is ignored.
+	XChaCha20Poly1305
+
+	// DES is the Data Encryption Standard defined in FIPS 46-3 with a 64-bit block and a 56-bit key (8 bytes with
+	// parity bits). Its key is too short to resist brute force attacks, so it is insecure.
+	DES
+
+	// TripleDES (3DES) applies DES three times with a 24 bytes key, a 16 bytes key is also accepted as the
+	// two-key variant. It has been deprecated by NIST because of its 64-bit block, so it is insecure.
+	TripleDES
+
+	// Blowfish is a 64-bit block cipher with a variable key size of 4 to 56 bytes designed by Bruce Schneier.
+	// It is vulnerable to birthday attacks because of its 64-bit block, so it is insecure.
+	Blowfish
 )
+
+// methodNames is the names of the methods.
+var methodNames = map[MethodType]string{
+	AES:               "AES",
+	CAST5:             "CAST5",
+	SM4:               "SM4",
+	Twofish:           "Twofish",
+	TEA:               "TEA",
+	XTEA:              "XTEA",
+	ChaCha20Poly1305:  "ChaCha20-Poly1305",
+	XChaCha20Poly1305: "XChaCha20-Poly1305",
+	DES:               "DES",
+	TripleDES:         "3DES",
+	Blowfish:          "Blowfish",
+}
+
+// String returns the name of the method such as AES.
+func (m MethodType) String() string {
+	if name, ok := methodNames[m]; ok {
+		return name
+	}
+	if name, ok := registeredName(m); ok {
+		return name
+	}
+	return fmt.Sprintf("MethodType(%d)", m)
+}
+
+// IsInsecure returns true if the method is only provided for legacy systems such as DES.
+func (m MethodType) IsInsecure() bool {
+	return m == DES || m == TripleDES || m == Blowfish
+}
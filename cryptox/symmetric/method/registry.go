@@ -0,0 +1,111 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package method
+
+import (
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Factory creates a cipher block from the key, it should return an error if the key is invalid.
+type Factory func(key []byte) (cipher.Block, error)
+
+// FirstRegistered is the smallest MethodType of the registered methods, the smaller values are reserved
+// for the built-in methods.
+const FirstRegistered MethodType = 128
+
+// registry stores the registered methods.
+var registry = struct {
+	sync.RWMutex
+	factories map[MethodType]Factory
+	names     map[MethodType]string
+	types     map[string]MethodType
+}{
+	factories: map[MethodType]Factory{},
+	names:     map[MethodType]string{},
+	types:     map[string]MethodType{},
+}
+
+// Register registers a method such as an in-house or HSM-backed cipher with the MethodType m, which can be used
+// by CryptoS.WithMethod like the built-in methods. The MethodType is stored in the envelope and file header, so it
+// must be stable across builds and processes. It must be at least FirstRegistered, and both the MethodType and
+// the name must be unique.
+func Register(m MethodType, name string, factory Factory) error {
+	if name == "" || factory == nil {
+		return errors.New("the name and factory of the method cannot be empty")
+	}
+
+	if m < FirstRegistered {
+		return fmt.Errorf("the method type %d is reserved for the built-in methods", m)
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if v, ok := registry.names[m]; ok {
+		return fmt.Errorf("the method type %d has been registered by %s", m, v)
+	}
+
+	if _, ok := registry.types[name]; ok {
+		return fmt.Errorf("the method %s has been registered", name)
+	}
+
+	for _, v := range methodNames {
+		if v == name {
+			return fmt.Errorf("the method %s is a built-in method", name)
+		}
+	}
+
+	registry.factories[m] = factory
+	registry.names[m] = name
+	registry.types[name] = m
+
+	return nil
+}
+
+// Lookup returns the factory of the registered method.
+func Lookup(m MethodType) (Factory, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	factory, ok := registry.factories[m]
+	return factory, ok
+}
+
+// registeredName returns the name of the registered method.
+func registeredName(m MethodType) (string, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	name, ok := registry.names[m]
+	return name, ok
+}
+
+// ByName returns the method with the name such as AES, including the registered methods.
+func ByName(name string) (MethodType, bool) {
+	for k, v := range methodNames {
+		if v == name {
+			return k, true
+		}
+	}
+
+	registry.RLock()
+	defer registry.RUnlock()
+
+	m, ok := registry.types[name]
+	return m, ok
+}
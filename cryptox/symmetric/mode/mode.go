@@ -14,6 +14,8 @@
 
 package mode
 
+import "fmt"
+
 // ModeType is the encrypted mode.
 type ModeType uint8
 
@@ -35,4 +37,45 @@ const (
 	// CTR is a Stream which encrypts/decrypts using the given Block in
 	// counter mode. The length of iv must be the same as the Block's block size.
 	CTR
+
+	// GCM (Galois/Counter Mode) is an authenticated encryption mode for 128-bit block ciphers such as AES and SM4.
+	// The IV is used as the nonce, and the authentication tag is appended to the ciphertext.
+	// The nonce must never be reused with the same key.
+	GCM
+
+	// ECB mode encrypts each block independently, identical plaintext blocks produce identical ciphertext blocks,
+	// so the patterns of the plaintext are not hidden. It needs no IV and is only provided for legacy interfaces,
+	// CryptoS requires AllowECB to be called before using it.
+	ECB
+
+	// CCM (Counter with CBC-MAC) is an authenticated encryption mode for 128-bit block ciphers such as AES and SM4,
+	// it is widely used by IoT and smart card protocols. The nonce size is between 7 and 13 bytes and the tag size
+	// can be shortened to 4 bytes. The nonce must never be reused with the same key.
+	CCM
+
+	// SIV (Synthetic Initialization Vector, RFC 5297) is a deterministic and nonce-misuse-resistant authenticated
+	// encryption mode for AES. The same plaintext and associated data always produce the same ciphertext, so it can
+	// be used for equality lookups. The key is double length: 32, 48 or 64 bytes for AES-128, AES-192 and AES-256.
+	// The nonce is optional, the synthetic IV is prepended to the ciphertext.
+	SIV
 )
+
+// modeNames is the names of the modes.
+var modeNames = map[ModeType]string{
+	CBC: "CBC",
+	CFB: "CFB",
+	OFB: "OFB",
+	CTR: "CTR",
+	GCM: "GCM",
+	ECB: "ECB",
+	CCM: "CCM",
+	SIV: "SIV",
+}
+
+// String returns the name of the mode such as CBC.
+func (m ModeType) String() string {
+	if name, ok := modeNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("ModeType(%d)", m)
+}
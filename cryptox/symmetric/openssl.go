@@ -0,0 +1,161 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"bytes"
+	"crypto"
+	_ "crypto/md5"
+	"crypto/rand"
+	_ "crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/suyuan32/knife/cryptox/symmetric/mode"
+	"github.com/suyuan32/knife/cryptox/symmetric/padding"
+)
+
+// The OpenSSL format is produced by "openssl enc" and CryptoJS with a passphrase, its layout is:
+//
+//	magic "Salted__" | salt (8 bytes) | ciphertext
+//
+// The key and IV are derived from the passphrase and salt by EVP_BytesToKey with one iteration.
+
+// opensslMagic is the magic at the beginning of the OpenSSL format.
+var opensslMagic = []byte("Salted__")
+
+// opensslSaltSize is the salt size of the OpenSSL format.
+const opensslSaltSize = 8
+
+// ErrInvalidOpenSSLData is returned when the data is not in the OpenSSL format.
+var ErrInvalidOpenSSLData = errors.New("invalid OpenSSL salted data")
+
+// EncryptOpenSSL encrypts the input data with the key and IV derived from the passphrase and a random salt, and
+// output data with the OpenSSL "Salted__" format which can be decrypted by "openssl enc -d" and CryptoJS.
+// The keySize is the key size in bytes such as 16 for "-aes-128-cbc" and 32 for "-aes-256-cbc" which is used by
+// CryptoJS. The digest is crypto.MD5 for CryptoJS and OpenSSL before 1.1.0, and crypto.SHA256 for the later OpenSSL.
+// The method and mode such as AES-CBC are used as configured, the padding is set as "openssl enc" does, which is
+// padding.PKCS7 for the CBC and ECB modes and padding.No for the other modes.
+func (s *CryptoS) EncryptOpenSSL(passphrase string, keySize int, digest crypto.Hash) *CryptoS {
+	salt := make([]byte, opensslSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		s.Errors = errors.Join(s.Errors, fmt.Errorf("failed to generate salt, error:%s", err))
+		return s
+	}
+
+	return s.encryptOpenSSL(passphrase, keySize, digest, salt)
+}
+
+// encryptOpenSSL encrypts the input data with the salt and output data with the OpenSSL format.
+func (s *CryptoS) encryptOpenSSL(passphrase string, keySize int, digest crypto.Hash, salt []byte) *CryptoS {
+	if err := s.keyFromOpenSSL(passphrase, keySize, digest, salt); err != nil {
+		s.Errors = errors.Join(s.Errors, err)
+		return s
+	}
+
+	if s.Encrypt(); s.Errors != nil {
+		return s
+	}
+
+	result := make([]byte, 0, len(opensslMagic)+len(salt)+len(s.OutputData))
+	result = append(result, opensslMagic...)
+	result = append(result, salt...)
+	s.OutputData = append(result, s.OutputData...)
+
+	return s
+}
+
+// DecryptOpenSSL decrypts the input data with the OpenSSL "Salted__" format produced by "openssl enc" and CryptoJS,
+// the key and IV are derived from the passphrase and the salt in the input data.
+// The keySize and digest are the same as EncryptOpenSSL.
+func (s *CryptoS) DecryptOpenSSL(passphrase string, keySize int, digest crypto.Hash) *CryptoS {
+	headerSize := len(opensslMagic) + opensslSaltSize
+	if len(s.InputData) < headerSize || !bytes.Equal(s.InputData[:len(opensslMagic)], opensslMagic) {
+		s.Errors = errors.Join(s.Errors, ErrInvalidOpenSSLData)
+		return s
+	}
+
+	if err := s.keyFromOpenSSL(passphrase, keySize, digest, s.InputData[len(opensslMagic):headerSize]); err != nil {
+		s.Errors = errors.Join(s.Errors, err)
+		return s
+	}
+
+	s.InputData = s.InputData[headerSize:]
+
+	return s.Decrypt()
+}
+
+// keyFromOpenSSL set the key and IV derived from the passphrase and salt by EVP_BytesToKey, and the padding used by
+// "openssl enc".
+func (s *CryptoS) keyFromOpenSSL(passphrase string, keySize int, digest crypto.Hash, salt []byte) error {
+	if s.isAEAD() {
+		return errors.New("the AEAD is not supported by the OpenSSL format")
+	}
+
+	if s.MAC != 0 || s.RandomIV {
+		return errors.New("the MAC and random IV are not supported by the OpenSSL format")
+	}
+
+	// the key of the handle would be used with the derived IV
+	if s.KeyHandle != nil {
+		return newValidationError("key", "the key handle cannot be used with the OpenSSL format")
+	}
+
+	// the key of the keyring would replace the key derived from the passphrase
+	if s.Keyring != nil {
+		return newValidationError("key", "the keyring cannot be used with the OpenSSL format")
+	}
+
+	if !digest.Available() {
+		return fmt.Errorf("the digest %s is not available", digest)
+	}
+
+	if keySize < 1 {
+		return fmt.Errorf("the key size must be positive, got %d", keySize)
+	}
+
+	if s.Mode == mode.CBC || s.Mode == mode.ECB {
+		s.Padding = padding.PKCS7
+	} else {
+		s.Padding = padding.No
+	}
+
+	result := evpBytesToKey(digest.New, []byte(passphrase), salt, keySize+s.ivSize(s.blockSize()))
+	s.Key = result[:keySize:keySize]
+	s.IV = result[keySize:]
+
+	return nil
+}
+
+// evpBytesToKey derives the key and IV with the EVP_BytesToKey of OpenSSL, the iteration count is 1:
+//
+//	D_i = HASH(D_(i-1) || password || salt), result = D_1 || D_2 || ...
+func evpBytesToKey(newHash func() hash.Hash, password, salt []byte, size int) []byte {
+	h := newHash()
+	result := make([]byte, 0, size+h.Size())
+
+	var prev []byte
+	for len(result) < size {
+		h.Reset()
+		h.Write(prev)
+		h.Write(password)
+		h.Write(salt)
+		prev = h.Sum(nil)
+		result = append(result, prev...)
+	}
+
+	return result[:size]
+}
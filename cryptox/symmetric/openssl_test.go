@@ -0,0 +1,192 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"crypto"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/suyuan32/knife/cryptox/keyhandle"
+	"github.com/suyuan32/knife/cryptox/symmetric/method"
+	"github.com/suyuan32/knife/cryptox/symmetric/mode"
+	"github.com/suyuan32/knife/cryptox/symmetric/padding"
+)
+
+func TestCryptoS_OpenSSL(t *testing.T) {
+	testStr := "hello openssl salted format"
+	salt := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+
+	// generated by "openssl enc -S 0102030405060708 -pass pass:secret", the header is prepended
+	tests := []struct {
+		name    string
+		method  method.MethodType
+		mode    mode.ModeType
+		keySize int
+		digest  crypto.Hash
+		want    string
+	}{
+		{
+			name:    "aes-256-cbc md5",
+			method:  method.AES,
+			mode:    mode.CBC,
+			keySize: 32,
+			digest:  crypto.MD5,
+			want:    "e93a53edf96fece25fac19f2d98979cff0259dee936592c81179a3672b2e2db6",
+		},
+		{
+			name:    "aes-256-cbc sha256",
+			method:  method.AES,
+			mode:    mode.CBC,
+			keySize: 32,
+			digest:  crypto.SHA256,
+			want:    "00f957892701219c62d19f27402189730b5d7b17dd0e440e88b8f0ddff91db49",
+		},
+		{
+			name:    "sm4-cbc sha256",
+			method:  method.SM4,
+			mode:    mode.CBC,
+			keySize: 16,
+			digest:  crypto.SHA256,
+			want:    "f9a39d60a37c9e4d0a53fe00edc859070978c88928982ecfd131105490538d7c",
+		},
+		{
+			name:    "aes-256-ctr sha256",
+			method:  method.AES,
+			mode:    mode.CTR,
+			keySize: 32,
+			digest:  crypto.SHA256,
+			want:    "130f87a1cb931df4af1e0cc80e4464b74898cb4c69fdaa79e508df",
+		},
+		{
+			name:    "aes-128-cbc sha256",
+			method:  method.AES,
+			mode:    mode.CBC,
+			keySize: 16,
+			digest:  crypto.SHA256,
+			want:    "0398430a75cb080d5b228297495469c1cd1a9e7ef0438dedbb27a189079f25ae",
+		},
+		{
+			name:    "aes-192-cbc sha256",
+			method:  method.AES,
+			mode:    mode.CBC,
+			keySize: 24,
+			digest:  crypto.SHA256,
+			want:    "c413bd84b56749bb9a017cf41be3571f60a7b4392713c3dbde8d053012d1a190",
+		},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			// the padding is set as "openssl enc" does
+			c := newTestCryptoS()
+			c.WithMethod(v.method).WithMode(v.mode).WithPadding(padding.Zero)
+
+			result, err := c.InputFromString(testStr).encryptOpenSSL("secret", v.keySize, v.digest, salt).ToHexString()
+			assert.Nil(t, err)
+			assert.Equal(t, "53616c7465645f5f0102030405060708"+v.want, result)
+
+			c.Reset()
+			c.WithMethod(v.method).WithMode(v.mode)
+
+			plaintext, err := c.InputFromHexString(result).DecryptOpenSSL("secret", v.keySize, v.digest).ToString()
+			assert.Nil(t, err)
+			assert.Equal(t, testStr, plaintext)
+		})
+	}
+
+	// generated by "openssl enc -aes-256-cbc -a -pass pass:secret" with a random salt
+	c := newTestCryptoS()
+	result, err := c.WithPadding(padding.PKCS7).
+		InputFromBase64String("U2FsdGVkX19s+bhe038K2W4AwtSfO40fUm9bPIsmG/bdizLZedpcC8xQGVVbRE0q\n").
+		DecryptOpenSSL("secret", 32, crypto.SHA256).ToString()
+	assert.Nil(t, err)
+	assert.Equal(t, testStr, result)
+
+	// generated by "openssl enc -aes-256-cbc -md md5 -a -pass pass:secret", the format of CryptoJS.AES.encrypt
+	c = newTestCryptoS()
+	result, err = c.WithPadding(padding.PKCS7).
+		InputFromBase64String("U2FsdGVkX18xZ/OG0Fsqmdr5M8k0NNA2vB3uUah5vK0RAoXW3mwhNQRhy4nFrhyW").
+		DecryptOpenSSL("secret", 32, crypto.MD5).ToString()
+	assert.Nil(t, err)
+	assert.Equal(t, testStr, result)
+
+	// generated by "openssl enc -aes-128-cbc -md md5 -a -pass pass:secret", the padding is not configured
+	c = newTestCryptoS()
+	result, err = c.InputFromBase64String("U2FsdGVkX1+Tz6H3VWpjv6Y9eNhE7w8Xud0EkmDePbw=").
+		DecryptOpenSSL("secret", 16, crypto.MD5).ToString()
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", result)
+
+	c = newTestCryptoS()
+	result, err = c.InputFromString("hello").EncryptOpenSSL("secret", 16, crypto.MD5).ToBase64String()
+	assert.Nil(t, err)
+	c = newTestCryptoS()
+	result, err = c.InputFromBase64String(result).DecryptOpenSSL("secret", 16, crypto.MD5).ToString()
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", result)
+
+	c = newTestCryptoS()
+	c.InputFromString("hello").EncryptOpenSSL("secret", 0, crypto.MD5)
+	assert.NotNil(t, c.Errors)
+
+	handle, err := keyhandle.Seal([]byte("1234567890123456"))
+	assert.Nil(t, err)
+	c = newTestCryptoS()
+	c.WithKeyHandle(handle).InputFromString("hello").EncryptOpenSSL("secret", 16, crypto.MD5)
+	var validationErr *ValidationError
+	assert.True(t, errors.As(c.Errors, &validationErr))
+
+	keyring := NewKeyring()
+	assert.Nil(t, keyring.Add("k1", []byte("1234567890123456")))
+	c = newTestCryptoS()
+	c.WithKeyring(keyring).InputFromString("hello").EncryptOpenSSL("secret", 16, crypto.MD5)
+	assert.True(t, errors.As(c.Errors, &validationErr))
+
+	c = newTestCryptoS()
+	salted, err := c.InputFromString("hello").EncryptOpenSSL("secret", 16, crypto.MD5).ToBytes()
+	assert.Nil(t, err)
+	c = newTestCryptoS()
+	c.WithKeyring(keyring).InputFromBytes(salted).DecryptOpenSSL("secret", 16, crypto.MD5)
+	assert.True(t, errors.As(c.Errors, &validationErr))
+
+	// the random salt is used
+	c = newTestCryptoS()
+	first, err := c.WithPadding(padding.PKCS7).InputFromString(testStr).EncryptOpenSSL("secret", 32, crypto.MD5).ToBytes()
+	assert.Nil(t, err)
+	c = newTestCryptoS()
+	second, err := c.WithPadding(padding.PKCS7).InputFromString(testStr).EncryptOpenSSL("secret", 32, crypto.MD5).ToBytes()
+	assert.Nil(t, err)
+	assert.NotEqual(t, first, second)
+
+	// the wrong passphrase is detected by the padding
+	c = newTestCryptoS()
+	c.WithPadding(padding.PKCS7).InputFromBytes(first).DecryptOpenSSL("wrong", 32, crypto.MD5)
+	assert.True(t, errors.Is(c.Errors, padding.ErrInvalidPadding))
+
+	c = newTestCryptoS()
+	c.WithPadding(padding.PKCS7).InputFromString("Salted_").DecryptOpenSSL("secret", 32, crypto.MD5)
+	assert.True(t, errors.Is(c.Errors, ErrInvalidOpenSSLData))
+
+	c = newTestCryptoS()
+	c.WithMode(mode.GCM).InputFromString(testStr).EncryptOpenSSL("secret", 32, crypto.MD5)
+	assert.NotNil(t, c.Errors)
+
+	c = newTestCryptoS()
+	c.WithPadding(padding.PKCS7).InputFromString(testStr).EncryptOpenSSL("secret", 32, crypto.MD4)
+	assert.NotNil(t, c.Errors)
+}
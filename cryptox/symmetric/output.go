@@ -15,8 +15,7 @@
 package symmetric
 
 import (
-	"encoding/base64"
-	"encoding/hex"
+	"github.com/suyuan32/knife/core/codec"
 )
 
 // ToString output data with string type.
@@ -31,10 +30,15 @@ func (s *CryptoS) ToBytes() ([]byte, error) {
 
 // ToBase64String output data with base64 string.
 func (s *CryptoS) ToBase64String() (string, error) {
-	return base64.StdEncoding.EncodeToString(s.OutputData), s.Errors
+	return s.ToEncodedString(codec.Base64Std)
 }
 
 // ToHexString output data with hex string.
 func (s *CryptoS) ToHexString() (string, error) {
-	return hex.EncodeToString(s.OutputData), s.Errors
+	return s.ToEncodedString(codec.Hex)
+}
+
+// ToEncodedString output data with string encoded by the encoding such as codec.Base64RawURL.
+func (s *CryptoS) ToEncodedString(encoding codec.Encoding) (string, error) {
+	return encoding.EncodeToString(s.OutputData), s.Errors
 }
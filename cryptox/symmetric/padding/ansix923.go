@@ -0,0 +1,57 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package padding
+
+import (
+	"bytes"
+	"crypto/subtle"
+)
+
+// PaddingANSIX923 add padding at the end of byte slice with zero bytes, and the last byte is the padding size.
+func PaddingANSIX923(data []byte, blockSize int) []byte {
+	dataLen := len(data)
+	if blockSize < 1 {
+		return data
+	}
+	paddingSize := blockSize - (dataLen % blockSize)
+	return append(append(data, bytes.Repeat([]byte{0}, paddingSize-1)...), byte(paddingSize))
+}
+
+// DePaddingANSIX923 remove ANSI X.923 padding at the end of byte slice.
+// The padding size must be between 1 and block size and the other padding bytes must be zero, otherwise
+// ErrInvalidPadding is returned. The padding bytes are checked in constant time.
+func DePaddingANSIX923(data []byte, blockSize int) ([]byte, error) {
+	dataLen := len(data)
+	if dataLen == 0 {
+		return nil, ErrInvalidPadding
+	}
+
+	if blockSize < 1 || blockSize > 255 || dataLen%blockSize != 0 {
+		return nil, ErrInvalidPadding
+	}
+
+	paddingSize := int(data[dataLen-1])
+	good := subtle.ConstantTimeLessOrEq(1, paddingSize) & subtle.ConstantTimeLessOrEq(paddingSize, blockSize)
+	for i := 1; i < blockSize; i++ {
+		inPadding := subtle.ConstantTimeLessOrEq(i+1, paddingSize)
+		good &= subtle.ConstantTimeByteEq(data[dataLen-1-i], 0) | (inPadding ^ 1)
+	}
+
+	if good != 1 {
+		return nil, ErrInvalidPadding
+	}
+
+	return data[:dataLen-paddingSize], nil
+}
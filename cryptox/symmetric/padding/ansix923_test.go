@@ -0,0 +1,104 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package padding
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPaddingANSIX923(t *testing.T) {
+	type args struct {
+		data      []byte
+		blockSize int
+	}
+	tests := []struct {
+		name string
+		args args
+		want []byte
+	}{
+		{
+			name: "test1",
+			args: args{data: []byte{104, 101, 108, 108, 111}, blockSize: 8},
+			want: []byte{104, 101, 108, 108, 111, 0, 0, 3},
+		},
+		{
+			name: "test2",
+			args: args{data: []byte{1, 2, 3, 4}, blockSize: 4},
+			want: []byte{1, 2, 3, 4, 0, 0, 0, 4},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PaddingANSIX923(tt.args.data, tt.args.blockSize); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PaddingANSIX923() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDePaddingANSIX923(t *testing.T) {
+	type args struct {
+		data      []byte
+		blockSize int
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name: "test1",
+			args: args{data: []byte{104, 101, 108, 108, 111, 0, 0, 3}, blockSize: 8},
+			want: []byte{104, 101, 108, 108, 111},
+		},
+		{
+			name: "full block of padding",
+			args: args{data: []byte{1, 2, 3, 4, 0, 0, 0, 4}, blockSize: 4},
+			want: []byte{1, 2, 3, 4},
+		},
+		{
+			name:    "non-zero padding bytes",
+			args:    args{data: []byte{1, 2, 1, 3}, blockSize: 4},
+			wantErr: true,
+		},
+		{
+			name:    "padding size larger than block size",
+			args:    args{data: []byte{0, 0, 0, 5}, blockSize: 4},
+			wantErr: true,
+		},
+		{
+			name:    "zero padding size",
+			args:    args{data: []byte{1, 2, 3, 0}, blockSize: 4},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DePaddingANSIX923(tt.args.data, tt.args.blockSize)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPadding) {
+					t.Errorf("DePaddingANSIX923() error = %v, want %v", err, ErrInvalidPadding)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DePaddingANSIX923() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
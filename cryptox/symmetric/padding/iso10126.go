@@ -0,0 +1,58 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package padding
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+)
+
+// PaddingISO10126 add padding at the end of byte slice with random bytes, and the last byte is the padding size.
+func PaddingISO10126(data []byte, blockSize int) ([]byte, error) {
+	dataLen := len(data)
+	if blockSize < 1 {
+		return data, nil
+	}
+	paddingSize := blockSize - (dataLen % blockSize)
+
+	padding := make([]byte, paddingSize)
+	if _, err := rand.Read(padding[:paddingSize-1]); err != nil {
+		return nil, err
+	}
+	padding[paddingSize-1] = byte(paddingSize)
+
+	return append(data, padding...), nil
+}
+
+// DePaddingISO10126 remove ISO 10126 padding at the end of byte slice.
+// The padding size must be between 1 and block size, otherwise ErrInvalidPadding is returned. The other padding
+// bytes are random, so they are not checked.
+func DePaddingISO10126(data []byte, blockSize int) ([]byte, error) {
+	dataLen := len(data)
+	if dataLen == 0 {
+		return nil, ErrInvalidPadding
+	}
+
+	if blockSize < 1 || blockSize > 255 || dataLen%blockSize != 0 {
+		return nil, ErrInvalidPadding
+	}
+
+	paddingSize := int(data[dataLen-1])
+	if subtle.ConstantTimeLessOrEq(1, paddingSize)&subtle.ConstantTimeLessOrEq(paddingSize, blockSize) != 1 {
+		return nil, ErrInvalidPadding
+	}
+
+	return data[:dataLen-paddingSize], nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package padding
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPaddingISO10126(t *testing.T) {
+	data := []byte{104, 101, 108, 108, 111}
+	first, err := PaddingISO10126(data, 16)
+	if err != nil || len(first) != 16 || first[15] != 11 || !reflect.DeepEqual(first[:5], data) {
+		t.Errorf("PaddingISO10126() = %v, %v", first, err)
+	}
+
+	second, err := PaddingISO10126([]byte{104, 101, 108, 108, 111}, 16)
+	if err != nil || reflect.DeepEqual(first, second) {
+		t.Errorf("PaddingISO10126() should be random, got %v and %v", first, second)
+	}
+
+	got, err := DePaddingISO10126(first, 16)
+	if err != nil || !reflect.DeepEqual(got, data) {
+		t.Errorf("DePaddingISO10126() = %v, %v, want %v", got, err, data)
+	}
+}
+
+func TestDePaddingISO10126(t *testing.T) {
+	type args struct {
+		data      []byte
+		blockSize int
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name: "test1",
+			args: args{data: []byte{104, 101, 108, 108, 111, 81, 200, 3}, blockSize: 8},
+			want: []byte{104, 101, 108, 108, 111},
+		},
+		{
+			name:    "padding size larger than block size",
+			args:    args{data: []byte{0, 0, 0, 5}, blockSize: 4},
+			wantErr: true,
+		},
+		{
+			name:    "zero padding size",
+			args:    args{data: []byte{1, 2, 3, 0}, blockSize: 4},
+			wantErr: true,
+		},
+		{
+			name:    "not a multiple of block size",
+			args:    args{data: []byte{1, 2, 1}, blockSize: 4},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DePaddingISO10126(tt.args.data, tt.args.blockSize)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPadding) {
+					t.Errorf("DePaddingISO10126() error = %v, want %v", err, ErrInvalidPadding)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DePaddingISO10126() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
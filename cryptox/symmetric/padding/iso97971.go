@@ -14,13 +14,45 @@
 
 package padding
 
-// PaddingISO97971 add padding at the end of byte slice with zero bytes which are separated by 0x80.
+import "crypto/subtle"
+
+// PaddingISO97971 add padding at the end of byte slice with the separator 0x80 followed by zero bytes.
+// The padding size is between 1 and block size, so the separator is always in the last block.
 func PaddingISO97971(data []byte, blockSize int) []byte {
-	return PaddingZero(append(data, 0x80), blockSize)
+	if blockSize < 1 {
+		return data
+	}
+
+	paddingSize := blockSize - (len(data) % blockSize)
+	data = append(data, 0x80)
+	return append(data, make([]byte, paddingSize-1)...)
 }
 
-// DePaddingISO97971 is similar with PKCS7, its block size is 8.
-func DePaddingISO97971(data []byte) []byte {
-	result := DePaddingZero(data)
-	return result[:len(result)-1]
+// DePaddingISO97971 remove the zero bytes and the separator 0x80 at the end of byte slice.
+// The separator must be in the last block and followed only by zero bytes, otherwise ErrInvalidPadding is returned.
+// The padding bytes are checked in constant time.
+func DePaddingISO97971(data []byte, blockSize int) ([]byte, error) {
+	dataLen := len(data)
+	if dataLen == 0 {
+		return nil, ErrInvalidPadding
+	}
+
+	if blockSize < 1 || dataLen%blockSize != 0 {
+		return nil, ErrInvalidPadding
+	}
+
+	found, bad, index := 0, 0, 0
+	for i := dataLen - 1; i >= dataLen-blockSize; i-- {
+		isSeparator := subtle.ConstantTimeByteEq(data[i], 0x80) & (found ^ 1)
+		isZero := subtle.ConstantTimeByteEq(data[i], 0)
+		bad |= (found ^ 1) & (isSeparator ^ 1) & (isZero ^ 1)
+		index = subtle.ConstantTimeSelect(isSeparator, i, index)
+		found |= isSeparator
+	}
+
+	if found&(bad^1) != 1 {
+		return nil, ErrInvalidPadding
+	}
+
+	return data[:index], nil
 }
@@ -15,29 +15,59 @@
 package padding
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 )
 
 func TestDePaddingISO97971(t *testing.T) {
 	type args struct {
-		data []byte
+		data      []byte
+		blockSize int
 	}
 	tests := []struct {
-		name string
-		args args
-		want []byte
+		name    string
+		args    args
+		want    []byte
+		wantErr bool
 	}{
 		{
 			name: "test1",
-			args: args{data: []byte{104, 101, 108, 108, 111, 32, 119, 111, 114, 108, 100, 0x80, 0, 0, 0, 0}},
+			args: args{data: []byte{104, 101, 108, 108, 111, 32, 119, 111, 114, 108, 100, 0x80, 0, 0, 0, 0}, blockSize: 8},
 			want: []byte{104, 101, 108, 108, 111, 32, 119, 111, 114, 108, 100},
 		},
+		{
+			name: "full block of padding",
+			args: args{data: []byte{1, 2, 3, 4, 0x80, 0, 0, 0}, blockSize: 4},
+			want: []byte{1, 2, 3, 4},
+		},
+		{
+			name:    "no separator",
+			args:    args{data: []byte{0, 0, 0, 0}, blockSize: 4},
+			wantErr: true,
+		},
+		{
+			name:    "non-zero byte after separator",
+			args:    args{data: []byte{1, 0x80, 0, 1}, blockSize: 4},
+			wantErr: true,
+		},
+		{
+			name:    "not a multiple of block size",
+			args:    args{data: []byte{1, 0x80, 0}, blockSize: 4},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := DePaddingISO97971(tt.args.data); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("DePaddingISO97971() = %v, want %v", got, tt.want)
+			got, err := DePaddingISO97971(tt.args.data, tt.args.blockSize)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPadding) {
+					t.Errorf("DePaddingISO97971() error = %v, want %v", err, ErrInvalidPadding)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DePaddingISO97971() = %v, %v, want %v", got, err, tt.want)
 			}
 		})
 	}
@@ -70,3 +100,37 @@ func TestPaddingISO97971(t *testing.T) {
 		})
 	}
 }
+
+func TestISO97971_RoundTrip(t *testing.T) {
+	for _, blockSize := range []int{8, 16} {
+		for length := 0; length <= 2*blockSize+1; length++ {
+			data := make([]byte, length)
+			for i := range data {
+				data[i] = byte(i + 1)
+			}
+
+			padded := PaddingISO97971(append([]byte(nil), data...), blockSize)
+			if paddingSize := len(padded) - length; paddingSize < 1 || paddingSize > blockSize ||
+				len(padded)%blockSize != 0 {
+				t.Errorf("PaddingISO97971() length %d, block size %d, got padded length %d",
+					length, blockSize, len(padded))
+			}
+
+			got, err := DePaddingISO97971(padded, blockSize)
+			if err != nil || !reflect.DeepEqual(got, data) {
+				t.Errorf("DePaddingISO97971() length %d, block size %d = %v, %v, want %v",
+					length, blockSize, got, err, data)
+			}
+		}
+	}
+}
+
+func TestPaddingISO97971_BlockSize(t *testing.T) {
+	if got := PaddingISO97971([]byte{1, 2}, 0); !reflect.DeepEqual(got, []byte{1, 2}) {
+		t.Errorf("PaddingISO97971() = %v, want %v", got, []byte{1, 2})
+	}
+
+	if _, err := Padding([]byte{1, 2}, ISO97971, 0); err == nil {
+		t.Error("Padding() should reject a block size of 0")
+	}
+}
@@ -1,5 +1,14 @@
 package padding
 
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidPadding is returned when the padding of data is corrupt, which is usually caused by a wrong key or
+// corrupt data.
+var ErrInvalidPadding = errors.New("invalid padding")
+
 // PaddingType is the padding method such as PKCS7.
 type PaddingType uint8
 
@@ -8,7 +17,8 @@ const (
 	ISO97971 PaddingType = 1 + iota
 	// No add no padding.
 	No
-	// PKCS5 is similar to PKCS7, but its block size is 8.
+	// PKCS5 is defined for 8 bytes block in RFC 2898, but it is used as PKCS7 with the block size of the method
+	// in Padding and DePadding, which is the same as PKCS5Padding of Java.
 	PKCS5
 	// PKCS7 padding is a generalization of PKCS5 padding (also known as standard padding). PKCS7 padding works by
 	// appending N bytes with the value of chr(N) , where N is the number of bytes required to make the final block of
@@ -16,38 +26,87 @@ const (
 	PKCS7
 	// Zero add padding at the end of byte slice with byte 0.
 	Zero
+	// ANSIX923 add padding at the end of byte slice with zero bytes and the last byte is the padding size.
+	ANSIX923
+	// ISO10126 add padding at the end of byte slice with random bytes and the last byte is the padding size.
+	ISO10126
 )
 
-// Padding pads data with method provided such as Zero Padding.
+// paddingNames is the names of the paddings.
+var paddingNames = map[PaddingType]string{
+	ISO97971: "ISO9797-1",
+	No:       "None",
+	PKCS5:    "PKCS5",
+	PKCS7:    "PKCS7",
+	Zero:     "Zero",
+	ANSIX923: "ANSIX9.23",
+	ISO10126: "ISO10126",
+}
+
+// String returns the name of the padding such as PKCS7.
+func (p PaddingType) String() string {
+	if name, ok := paddingNames[p]; ok {
+		return name
+	}
+	if r, ok := lookup(p); ok {
+		return r.name
+	}
+	return fmt.Sprintf("PaddingType(%d)", p)
+}
+
+// Padding pads data with method provided such as Zero Padding. It returns an error if the method is not supported
+// or the block size is not positive, use No to keep the data unchanged.
 func Padding(data []byte, method PaddingType, blockSize int) ([]byte, error) {
+	if blockSize < 1 && method != No {
+		return nil, fmt.Errorf("the block size must be positive, got %d", blockSize)
+	}
+
 	switch method {
 	case Zero:
 		return PaddingZero(data, blockSize), nil
 	case PKCS5:
-		return PaddingPKCS5(data), nil
+		return PaddingPKCS5(data, blockSize), nil
 	case PKCS7:
 		return PaddingPKCS7(data, blockSize), nil
 	case ISO97971:
 		return PaddingISO97971(data, blockSize), nil
+	case ANSIX923:
+		return PaddingANSIX923(data, blockSize), nil
+	case ISO10126:
+		return PaddingISO10126(data, blockSize)
 	case No:
 		return data, nil
 	}
-	return data, nil
+
+	if r, ok := lookup(method); ok {
+		return r.padding(data, blockSize)
+	}
+	return nil, fmt.Errorf("the padding %s is not supported", method)
 }
 
-// DePadding depads data with method provided such as Zero Padding.
+// DePadding depads data with method provided such as Zero Padding. It returns ErrInvalidPadding if the padding is
+// corrupt, the empty data is also invalid because the padding is at least one byte. It returns an error if the
+// method is not supported.
 func DePadding(data []byte, method PaddingType, blockSize int) ([]byte, error) {
 	switch method {
 	case Zero:
-		return DePaddingZero(data), nil
+		return DePaddingZero(data, blockSize)
 	case PKCS5:
-		return DePaddingPKCS5(data), nil
+		return DePaddingPKCS5(data, blockSize)
 	case PKCS7:
-		return DePaddingPKCS7(data), nil
+		return DePaddingPKCS7(data, blockSize)
 	case ISO97971:
-		return DePaddingISO97971(data), nil
+		return DePaddingISO97971(data, blockSize)
+	case ANSIX923:
+		return DePaddingANSIX923(data, blockSize)
+	case ISO10126:
+		return DePaddingISO10126(data, blockSize)
 	case No:
 		return data, nil
 	}
-	return data, nil
+
+	if r, ok := lookup(method); ok {
+		return r.depad(data, blockSize)
+	}
+	return nil, fmt.Errorf("the padding %s is not supported", method)
 }
@@ -1,6 +1,8 @@
 package padding
 
 import (
+	"bytes"
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -52,7 +54,7 @@ func TestDePadding(t *testing.T) {
 			args: args{
 				data:      []byte{0, 0, 6, 6, 6, 6, 6, 6},
 				method:    PKCS5,
-				blockSize: 4,
+				blockSize: 8,
 			},
 			want:    []byte{0, 0},
 			wantErr: false,
@@ -77,6 +79,46 @@ func TestDePadding(t *testing.T) {
 			want:    []byte{0, 0},
 			wantErr: false,
 		},
+		{
+			name: "test7",
+			args: args{
+				data:      []byte{0, 0, 3, 3},
+				method:    PKCS7,
+				blockSize: 4,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "test8",
+			args: args{
+				data:      append([]byte{1, 1, 1}, bytes.Repeat([]byte{13}, 13)...),
+				method:    PKCS5,
+				blockSize: 16,
+			},
+			want:    []byte{1, 1, 1},
+			wantErr: false,
+		},
+		{
+			name: "test9",
+			args: args{
+				data:      []byte{1, 0, 0, 3},
+				method:    ANSIX923,
+				blockSize: 4,
+			},
+			want:    []byte{1},
+			wantErr: false,
+		},
+		{
+			name: "test10",
+			args: args{
+				data:      []byte{1, 7, 9, 3},
+				method:    ISO10126,
+				blockSize: 4,
+			},
+			want:    []byte{1},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -141,7 +183,7 @@ func TestPadding(t *testing.T) {
 				method:    PKCS5,
 				blockSize: 4,
 			},
-			want:    []byte{0, 0, 6, 6, 6, 6, 6, 6},
+			want:    []byte{0, 0, 2, 2},
 			wantErr: false,
 		},
 		{
@@ -154,6 +196,26 @@ func TestPadding(t *testing.T) {
 			want:    []byte{0, 0, 2, 2},
 			wantErr: false,
 		},
+		{
+			name: "test6",
+			args: args{
+				data:      []byte{1, 1, 1},
+				method:    PKCS5,
+				blockSize: 16,
+			},
+			want:    append([]byte{1, 1, 1}, bytes.Repeat([]byte{13}, 13)...),
+			wantErr: false,
+		},
+		{
+			name: "test7",
+			args: args{
+				data:      []byte{1},
+				method:    ANSIX923,
+				blockSize: 4,
+			},
+			want:    []byte{1, 0, 0, 3},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -168,3 +230,33 @@ func TestPadding(t *testing.T) {
 		})
 	}
 }
+
+func TestDePadding_Empty(t *testing.T) {
+	for _, method := range []PaddingType{Zero, PKCS5, PKCS7, ISO97971, ANSIX923, ISO10126} {
+		if _, err := DePadding([]byte{}, method, 16); !errors.Is(err, ErrInvalidPadding) {
+			t.Errorf("DePadding() %s error = %v, want %v", method, err, ErrInvalidPadding)
+		}
+
+		// the empty data is padded to a full block
+		padded, err := Padding([]byte{}, method, 16)
+		if err != nil || len(padded) != 16 {
+			t.Errorf("Padding() %s = %v, %v, want a full block", method, padded, err)
+		}
+
+		if data, err := DePadding(padded, method, 16); err != nil || len(data) != 0 {
+			t.Errorf("DePadding() %s = %v, %v, want empty data", method, data, err)
+		}
+	}
+}
+
+func TestPadding_Unsupported(t *testing.T) {
+	for _, method := range []PaddingType{0, 99, FirstRegistered + 99} {
+		if _, err := Padding([]byte{1, 2}, method, 4); err == nil {
+			t.Errorf("Padding() %d error = nil, want an unsupported padding error", method)
+		}
+
+		if _, err := DePadding([]byte{1, 2, 2, 2}, method, 4); err == nil {
+			t.Errorf("DePadding() %d error = nil, want an unsupported padding error", method)
+		}
+	}
+}
@@ -2,13 +2,14 @@ package padding
 
 import (
 	"bytes"
+	"crypto/subtle"
 )
 
 // PaddingPKCS7 add padding at the end of byte slice with PKCS7 bytes.
 // PKCS7 padding is a generalization of PKCS5 padding (also known as standard padding). PKCS7 padding works by appending N bytes with the value of chr(N) , where N is the number of bytes required to make the final block of data the same size as the block size.
 func PaddingPKCS7(data []byte, blockSize int) []byte {
 	dataLen := len(data)
-	if dataLen == 0 || blockSize < 1 {
+	if blockSize < 1 {
 		return data
 	}
 	paddingSize := blockSize - (dataLen % blockSize)
@@ -16,24 +17,39 @@ func PaddingPKCS7(data []byte, blockSize int) []byte {
 }
 
 // DePaddingPKCS7 remove PKCS7 padding at the end of byte slice.
-func DePaddingPKCS7(data []byte) []byte {
-	if dataLen := len(data); dataLen == 0 {
-		return data
-	} else {
-		if dataLeft := dataLen - int(data[dataLen-1]); dataLeft > 0 {
-			return data[:dataLeft]
-		} else {
-			return data
-		}
+// The padding size must be between 1 and block size and all padding bytes must be equal to it, otherwise
+// ErrInvalidPadding is returned. The padding bytes are checked in constant time.
+func DePaddingPKCS7(data []byte, blockSize int) ([]byte, error) {
+	dataLen := len(data)
+	if dataLen == 0 {
+		return nil, ErrInvalidPadding
 	}
+
+	if blockSize < 1 || blockSize > 255 || dataLen%blockSize != 0 {
+		return nil, ErrInvalidPadding
+	}
+
+	paddingSize := int(data[dataLen-1])
+	good := subtle.ConstantTimeLessOrEq(1, paddingSize) & subtle.ConstantTimeLessOrEq(paddingSize, blockSize)
+	for i := 0; i < blockSize; i++ {
+		inPadding := subtle.ConstantTimeLessOrEq(i+1, paddingSize)
+		good &= subtle.ConstantTimeByteEq(data[dataLen-1-i], byte(paddingSize)) | (inPadding ^ 1)
+	}
+
+	if good != 1 {
+		return nil, ErrInvalidPadding
+	}
+
+	return data[:dataLen-paddingSize], nil
 }
 
-// PaddingPKCS5 is similar with PKCS7, its block size is 8.
-func PaddingPKCS5(data []byte) []byte {
-	return PaddingPKCS7(data, 8)
+// PaddingPKCS5 is the same as PKCS7 with the block size provided. RFC 2898 defines it for 8 bytes block only, but
+// it is used with the block size of the method as PKCS5Padding of Java.
+func PaddingPKCS5(data []byte, blockSize int) []byte {
+	return PaddingPKCS7(data, blockSize)
 }
 
-// DePaddingPKCS5 is similar with PKCS7, its block size is 8.
-func DePaddingPKCS5(data []byte) []byte {
-	return DePaddingPKCS7(data)
+// DePaddingPKCS5 is the same as DePaddingPKCS7 with the block size provided.
+func DePaddingPKCS5(data []byte, blockSize int) ([]byte, error) {
+	return DePaddingPKCS7(data, blockSize)
 }
@@ -1,29 +1,65 @@
 package padding
 
 import (
+	"bytes"
+	"errors"
 	"reflect"
 	"testing"
 )
 
 func TestDePaddingPKCS7(t *testing.T) {
 	type args struct {
-		data []byte
+		data      []byte
+		blockSize int
 	}
 	tests := []struct {
-		name string
-		args args
-		want []byte
+		name    string
+		args    args
+		want    []byte
+		wantErr bool
 	}{
 		{
 			name: "test1",
-			args: args{data: []byte{104, 101, 108, 108, 111, 32, 119, 111, 114, 6, 6, 6, 6, 6, 6}},
+			args: args{data: []byte{104, 101, 108, 108, 111, 32, 119, 111, 114, 6, 6, 6, 6, 6, 6}, blockSize: 15},
 			want: []byte{104, 101, 108, 108, 111, 32, 119, 111, 114},
 		},
+		{
+			name: "test2",
+			args: args{data: []byte{4, 4, 4, 4}, blockSize: 4},
+			want: []byte{},
+		},
+		{
+			name:    "zero padding size",
+			args:    args{data: []byte{1, 2, 3, 0}, blockSize: 4},
+			wantErr: true,
+		},
+		{
+			name:    "padding size larger than block size",
+			args:    args{data: []byte{5, 5, 5, 5}, blockSize: 4},
+			wantErr: true,
+		},
+		{
+			name:    "inconsistent padding bytes",
+			args:    args{data: []byte{1, 2, 1, 3, 3}, blockSize: 5},
+			wantErr: true,
+		},
+		{
+			name:    "not a multiple of block size",
+			args:    args{data: []byte{1, 2, 2}, blockSize: 4},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := DePaddingPKCS7(tt.args.data); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("DePaddingPKCS7() = %v, want %v", got, tt.want)
+			got, err := DePaddingPKCS7(tt.args.data, tt.args.blockSize)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPadding) {
+					t.Errorf("DePaddingPKCS7() error = %v, want %v", err, ErrInvalidPadding)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DePaddingPKCS7() = %v, %v, want %v", got, err, tt.want)
 			}
 		})
 	}
@@ -67,7 +103,8 @@ func TestPaddingPKCS7(t *testing.T) {
 
 func TestPaddingPKCS5(t *testing.T) {
 	type args struct {
-		data []byte
+		data      []byte
+		blockSize int
 	}
 	tests := []struct {
 		name string
@@ -77,14 +114,31 @@ func TestPaddingPKCS5(t *testing.T) {
 		{
 			name: "test1",
 			args: args{
-				data: []byte{104, 101, 108, 108, 111, 32, 119, 111, 114},
+				data:      []byte{104, 101, 108, 108, 111, 32, 119, 111, 114},
+				blockSize: 8,
+			},
+			want: []byte{104, 101, 108, 108, 111, 32, 119, 111, 114, 7, 7, 7, 7, 7, 7, 7},
+		},
+		{
+			name: "block size 16",
+			args: args{
+				data:      []byte{104, 101, 108, 108, 111, 32, 119, 111, 114},
+				blockSize: 16,
 			},
 			want: []byte{104, 101, 108, 108, 111, 32, 119, 111, 114, 7, 7, 7, 7, 7, 7, 7},
 		},
+		{
+			name: "block size 4",
+			args: args{
+				data:      []byte{104, 101, 108, 108, 111, 32, 119, 111, 114},
+				blockSize: 4,
+			},
+			want: []byte{104, 101, 108, 108, 111, 32, 119, 111, 114, 3, 3, 3},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := PaddingPKCS5(tt.args.data); !reflect.DeepEqual(got, tt.want) {
+			if got := PaddingPKCS5(tt.args.data, tt.args.blockSize); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("PaddingPKCS5() = %v, want %v", got, tt.want)
 			}
 		})
@@ -93,25 +147,51 @@ func TestPaddingPKCS5(t *testing.T) {
 
 func TestDePaddingPKCS5(t *testing.T) {
 	type args struct {
-		data []byte
+		data      []byte
+		blockSize int
 	}
 	tests := []struct {
-		name string
-		args args
-		want []byte
+		name    string
+		args    args
+		want    []byte
+		wantErr bool
 	}{
 		{
 			name: "test1",
 			args: args{
-				data: []byte{104, 101, 108, 108, 111, 32, 119, 111, 114, 7, 7, 7, 7, 7, 7, 7},
+				data:      []byte{104, 101, 108, 108, 111, 32, 119, 111, 114, 7, 7, 7, 7, 7, 7, 7},
+				blockSize: 8,
 			},
 			want: []byte{104, 101, 108, 108, 111, 32, 119, 111, 114},
 		},
+		{
+			name: "block size 16",
+			args: args{
+				data:      append([]byte{1, 1, 1}, bytes.Repeat([]byte{13}, 13)...),
+				blockSize: 16,
+			},
+			want: []byte{1, 1, 1},
+		},
+		{
+			name: "padding size larger than block size",
+			args: args{
+				data:      append([]byte{1, 1, 1}, bytes.Repeat([]byte{13}, 13)...),
+				blockSize: 8,
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := DePaddingPKCS5(tt.args.data); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("DePaddingPKCS5() = %v, want %v", got, tt.want)
+			got, err := DePaddingPKCS5(tt.args.data, tt.args.blockSize)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPadding) {
+					t.Errorf("DePaddingPKCS5() error = %v, want %v", err, ErrInvalidPadding)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DePaddingPKCS5() = %v, %v, want %v", got, err, tt.want)
 			}
 		})
 	}
@@ -0,0 +1,107 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package padding
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// PaddingFunc pads the data to a multiple of the block size.
+type PaddingFunc func(data []byte, blockSize int) ([]byte, error)
+
+// DePaddingFunc removes the padding from the data, it should return ErrInvalidPadding if the padding is corrupt.
+type DePaddingFunc func(data []byte, blockSize int) ([]byte, error)
+
+// FirstRegistered is the smallest PaddingType of the registered paddings, the smaller values are reserved
+// for the built-in paddings.
+const FirstRegistered PaddingType = 128
+
+type registration struct {
+	name    string
+	padding PaddingFunc
+	depad   DePaddingFunc
+}
+
+// registry stores the registered paddings.
+var registry = struct {
+	sync.RWMutex
+	paddings map[PaddingType]registration
+	types    map[string]PaddingType
+}{
+	paddings: map[PaddingType]registration{},
+	types:    map[string]PaddingType{},
+}
+
+// Register registers a padding with the PaddingType p, which can be used by Padding, DePadding and
+// CryptoS.WithPadding like the built-in paddings. The PaddingType is stored in the envelope and file header, so it
+// must be stable across builds and processes. It must be at least FirstRegistered, and both the PaddingType and
+// the name must be unique.
+func Register(p PaddingType, name string, padding PaddingFunc, depad DePaddingFunc) error {
+	if name == "" || padding == nil || depad == nil {
+		return errors.New("the name and functions of the padding cannot be empty")
+	}
+
+	if p < FirstRegistered {
+		return fmt.Errorf("the padding type %d is reserved for the built-in paddings", p)
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if r, ok := registry.paddings[p]; ok {
+		return fmt.Errorf("the padding type %d has been registered by %s", p, r.name)
+	}
+
+	if _, ok := registry.types[name]; ok {
+		return fmt.Errorf("the padding %s has been registered", name)
+	}
+
+	for _, v := range paddingNames {
+		if v == name {
+			return fmt.Errorf("the padding %s is a built-in padding", name)
+		}
+	}
+
+	registry.paddings[p] = registration{name: name, padding: padding, depad: depad}
+	registry.types[name] = p
+
+	return nil
+}
+
+// lookup returns the registered padding.
+func lookup(p PaddingType) (registration, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	r, ok := registry.paddings[p]
+	return r, ok
+}
+
+// ByName returns the padding with the name such as PKCS7, including the registered paddings.
+func ByName(name string) (PaddingType, bool) {
+	for k, v := range paddingNames {
+		if v == name {
+			return k, true
+		}
+	}
+
+	registry.RLock()
+	defer registry.RUnlock()
+
+	p, ok := registry.types[name]
+	return p, ok
+}
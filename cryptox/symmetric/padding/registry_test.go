@@ -0,0 +1,90 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package padding
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestRegister(t *testing.T) {
+	// pads with 0x80 and then 0xff, which is only used for testing
+	pad := func(data []byte, blockSize int) ([]byte, error) {
+		result := append(data[:len(data):len(data)], 0x80)
+		for len(result)%blockSize != 0 {
+			result = append(result, 0xff)
+		}
+		return result, nil
+	}
+	depad := func(data []byte, blockSize int) ([]byte, error) {
+		index := bytes.LastIndexByte(data, 0x80)
+		if index < 0 || len(data)-index > blockSize {
+			return nil, ErrInvalidPadding
+		}
+		return data[:index], nil
+	}
+
+	p := FirstRegistered + 72
+	if err := Register(p, "test-registry", pad, depad); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	padded, err := Padding([]byte{1, 2, 3}, p, 8)
+	if err != nil || !bytes.Equal(padded, []byte{1, 2, 3, 0x80, 0xff, 0xff, 0xff, 0xff}) {
+		t.Errorf("Padding() = %v, %v", padded, err)
+	}
+
+	data, err := DePadding(padded, p, 8)
+	if err != nil || !bytes.Equal(data, []byte{1, 2, 3}) {
+		t.Errorf("DePadding() = %v, %v", data, err)
+	}
+
+	if _, err := DePadding([]byte{1, 2, 3, 4, 5, 6, 7, 8}, p, 8); !errors.Is(err, ErrInvalidPadding) {
+		t.Errorf("DePadding() error = %v, want %v", err, ErrInvalidPadding)
+	}
+
+	if p.String() != "test-registry" {
+		t.Errorf("String() = %s, want test-registry", p)
+	}
+
+	if result, ok := ByName("test-registry"); !ok || result != p {
+		t.Errorf("ByName() = %d, %v, want %d", result, ok, p)
+	}
+
+	if result, ok := ByName("PKCS7"); !ok || result != PKCS7 {
+		t.Errorf("ByName() = %d, %v, want %d", result, ok, PKCS7)
+	}
+
+	if err := Register(p+1, "PKCS7", pad, depad); err == nil {
+		t.Error("Register() should reject a built-in name")
+	}
+
+	if err := Register(p+1, "test-registry", pad, depad); err == nil {
+		t.Error("Register() should reject a duplicate name")
+	}
+
+	if err := Register(p, "test-duplicate", pad, depad); err == nil {
+		t.Error("Register() should reject a duplicate padding type")
+	}
+
+	if err := Register(PKCS7, "test-reserved", pad, depad); err == nil {
+		t.Error("Register() should reject a reserved padding type")
+	}
+
+	if err := Register(p+1, "test-nil", nil, depad); err == nil {
+		t.Error("Register() should reject nil functions")
+	}
+}
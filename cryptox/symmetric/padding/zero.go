@@ -7,13 +7,26 @@ import (
 // PaddingZero add padding at the end of byte slice with byte 0.
 func PaddingZero(data []byte, blockSize int) []byte {
 	dataLen := len(data)
-	if dataLen == 0 || blockSize < 1 {
+	if blockSize < 1 {
 		return data
 	}
 	return append(data, bytes.Repeat([]byte{byte(0)}, blockSize-(dataLen%blockSize))...)
 }
 
 // DePaddingZero remove zero padding at the end of byte slice.
-func DePaddingZero(data []byte) []byte {
-	return bytes.TrimRight(data, string([]byte{0}))
+// The data size must be an integer multiple of block size and end with byte 0, otherwise ErrInvalidPadding is returned.
+// The zero bytes before the final block are kept as data.
+func DePaddingZero(data []byte, blockSize int) ([]byte, error) {
+	dataLen := len(data)
+	if dataLen == 0 {
+		return nil, ErrInvalidPadding
+	}
+
+	if blockSize < 1 || dataLen%blockSize != 0 || data[dataLen-1] != 0 {
+		return nil, ErrInvalidPadding
+	}
+
+	// the padding is 1 to block size zero bytes, so only the zero bytes of the final block are removed
+	lastBlock := bytes.TrimRight(data[dataLen-blockSize:], string([]byte{0}))
+	return data[:dataLen-blockSize+len(lastBlock)], nil
 }
@@ -1,6 +1,7 @@
 package padding
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -52,28 +53,52 @@ func TestPaddingZero(t *testing.T) {
 
 func TestDePaddingZero(t *testing.T) {
 	type args struct {
-		data []byte
+		data      []byte
+		blockSize int
 	}
 	tests := []struct {
-		name string
-		args args
-		want []byte
+		name    string
+		args    args
+		want    []byte
+		wantErr bool
 	}{
 		{
 			name: "test1",
-			args: args{data: []byte{104, 101, 108, 108, 111, 32, 119, 111, 114, 108, 100, 0, 0, 0, 0, 0, 0, 0, 0, 0}},
+			args: args{data: []byte{104, 101, 108, 108, 111, 32, 119, 111, 114, 108, 100, 0, 0, 0, 0, 0, 0, 0, 0, 0}, blockSize: 20},
 			want: []byte{104, 101, 108, 108, 111, 32, 119, 111, 114, 108, 100},
 		},
 		{
-			name: "test2",
-			args: args{},
-			want: nil,
+			name:    "empty data",
+			args:    args{blockSize: 8},
+			wantErr: true,
+		},
+		{
+			name: "zero bytes across blocks",
+			args: args{data: append([]byte("ab"), make([]byte, 30)...), blockSize: 16},
+			want: append([]byte("ab"), make([]byte, 14)...),
+		},
+		{
+			name:    "no zero byte",
+			args:    args{data: []byte{1, 2, 3, 4}, blockSize: 4},
+			wantErr: true,
+		},
+		{
+			name:    "not a multiple of block size",
+			args:    args{data: []byte{1, 0, 0}, blockSize: 4},
+			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := DePaddingZero(tt.args.data); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("DePaddingZero() = %v, want %v", got, tt.want)
+			got, err := DePaddingZero(tt.args.data, tt.args.blockSize)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPadding) {
+					t.Errorf("DePaddingZero() error = %v, want %v", err, ErrInvalidPadding)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DePaddingZero() = %v, %v, want %v", got, err, tt.want)
 			}
 		})
 	}
@@ -0,0 +1,163 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/suyuan32/knife/cryptox/symmetric/method"
+	"github.com/suyuan32/knife/cryptox/symmetric/mode"
+	"github.com/suyuan32/knife/cryptox/symmetric/padding"
+)
+
+func TestCryptoS_RegisteredMethod(t *testing.T) {
+	// wraps AES to act as an in-house cipher
+	m := method.FirstRegistered + 72
+	err := method.Register(m, "TestAES", func(key []byte) (cipher.Block, error) {
+		if len(key) != 32 {
+			return nil, errors.New("the key must be 32 bytes")
+		}
+		return aes.NewCipher(key)
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "TestAES", m.String())
+
+	result, ok := method.ByName("TestAES")
+	assert.True(t, ok)
+	assert.Equal(t, m, result)
+
+	c := newTestCryptoS()
+	c.WithTransformation("TestAES/CTR/NoPadding")
+	assert.Nil(t, c.Errors)
+	assert.Equal(t, m, c.Method)
+
+	err = method.Register(m+1, "TestAES", func(key []byte) (cipher.Block, error) { return aes.NewCipher(key) })
+	assert.NotNil(t, err)
+
+	err = method.Register(m+1, "AES", func(key []byte) (cipher.Block, error) { return aes.NewCipher(key) })
+	assert.NotNil(t, err)
+
+	err = method.Register(m, "TestAES2", func(key []byte) (cipher.Block, error) { return aes.NewCipher(key) })
+	assert.NotNil(t, err)
+
+	err = method.Register(method.AES, "TestAES3", func(key []byte) (cipher.Block, error) { return aes.NewCipher(key) })
+	assert.NotNil(t, err)
+
+	key := "12345678901234567890123456789012"
+	iv := "1234567890123456"
+
+	want := newTestCryptoS()
+	want.WithMethod(method.AES).WithMode(mode.CBC).WithPadding(padding.PKCS7).KeyFromString(key).
+		IVFromString(iv).InputFromString("registered method").Encrypt()
+	assert.Nil(t, want.Errors)
+
+	c = newTestCryptoS()
+	c.WithMethod(m).WithMode(mode.CBC).WithPadding(padding.PKCS7).KeyFromString(key).
+		IVFromString(iv).InputFromString("registered method").Encrypt()
+	assert.Nil(t, c.Errors)
+	assert.Equal(t, want.OutputData, c.OutputData)
+
+	c.InputData = c.OutputData
+	c.Decrypt()
+	assert.Nil(t, c.Errors)
+	assert.Equal(t, "registered method", string(c.OutputData))
+
+	c = newTestCryptoS()
+	c.WithMethod(m).WithMode(mode.GCM).KeyFromString(key).IVFromString(iv[:12]).
+		InputFromString("registered method").Encrypt()
+	assert.Nil(t, c.Errors)
+
+	// the key is validated by the factory
+	c = newTestCryptoS()
+	c.WithMethod(m).WithMode(mode.CBC).WithPadding(padding.PKCS7).KeyFromString(key[:16]).
+		IVFromString(iv).InputFromString("registered method").Encrypt()
+	var validationErr *ValidationError
+	assert.True(t, errors.As(c.Errors, &validationErr))
+	assert.Equal(t, "key", validationErr.Field)
+}
+
+func TestCryptoS_RegisteredPadding(t *testing.T) {
+	// the same as PKCS7, it only checks the registered padding is used by CryptoS
+	p := padding.FirstRegistered + 72
+	err := padding.Register(p, "TestPKCS7", func(data []byte, blockSize int) ([]byte, error) {
+		return padding.PaddingPKCS7(data, blockSize), nil
+	}, padding.DePaddingPKCS7)
+	assert.Nil(t, err)
+
+	key := "1234567890123456"
+	iv := "1234567890123456"
+
+	want := newTestCryptoS()
+	want.WithMethod(method.SM4).WithMode(mode.CBC).WithPadding(padding.PKCS7).KeyFromString(key).
+		IVFromString(iv).InputFromString("registered padding").Encrypt()
+	assert.Nil(t, want.Errors)
+
+	c := newTestCryptoS()
+	c.WithMethod(method.SM4).WithMode(mode.CBC).WithPadding(p).KeyFromString(key).
+		IVFromString(iv).InputFromString("registered padding").Encrypt()
+	assert.Nil(t, c.Errors)
+	assert.Equal(t, want.OutputData, c.OutputData)
+
+	c.InputData = c.OutputData
+	c.Decrypt()
+	assert.Nil(t, c.Errors)
+	assert.Equal(t, "registered padding", string(c.OutputData))
+}
+
+func TestCryptoS_RegisteredMethod_Factory(t *testing.T) {
+	// counts the cipher blocks created by each operation
+	var count int
+	m := method.FirstRegistered + 74
+	err := method.Register(m, "TestCountedAES", func(key []byte) (cipher.Block, error) {
+		count++
+		return aes.NewCipher(key)
+	})
+	assert.Nil(t, err)
+
+	key := "1234567890123456"
+	iv := "1234567890123456"
+
+	tests := []struct {
+		mode    mode.ModeType
+		padding padding.PaddingType
+		iv      string
+	}{
+		{mode: mode.CBC, padding: padding.PKCS7, iv: iv},
+		{mode: mode.CTR, iv: iv},
+		{mode: mode.GCM, iv: iv[:12]},
+	}
+	for _, tt := range tests {
+		c := newTestCryptoS()
+		c.WithMethod(m).WithMode(tt.mode).WithPadding(tt.padding).KeyFromString(key).IVFromString(tt.iv).
+			InputFromString("registered method")
+
+		count = 0
+		c.Encrypt()
+		assert.Nil(t, c.Errors)
+		assert.Equal(t, 1, count, tt.mode.String())
+
+		count = 0
+		c.InputData = c.OutputData
+		c.Decrypt()
+		assert.Nil(t, c.Errors)
+		assert.Equal(t, 1, count, tt.mode.String())
+		assert.Equal(t, "registered method", string(c.OutputData))
+	}
+}
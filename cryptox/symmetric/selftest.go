@@ -0,0 +1,600 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/suyuan32/knife/cryptox/hash/sm3"
+	"github.com/suyuan32/knife/cryptox/symmetric/mac"
+	"github.com/suyuan32/knife/cryptox/symmetric/method"
+	"github.com/suyuan32/knife/cryptox/symmetric/mode"
+	"github.com/suyuan32/knife/cryptox/symmetric/padding"
+)
+
+// SelfTestResult is the result of a self-test case.
+type SelfTestResult struct {
+	// Name is the name of the case such as "KAT AES-128 FIPS-197".
+	Name string
+
+	// Skipped is true if the combination is not supported, the reason is recorded in Detail.
+	Skipped bool
+
+	// Detail is the reason of skipping.
+	Detail string
+
+	// Err is the error if the case fails.
+	Err error
+}
+
+// SelfTestReport is the report of SelfTest.
+type SelfTestReport struct {
+	Results []SelfTestResult
+}
+
+// Passed returns true if no case fails.
+func (r *SelfTestReport) Passed() bool {
+	return r.Err() == nil
+}
+
+// Err returns the joined errors of the failed cases, it returns nil if no case fails.
+func (r *SelfTestReport) Err() error {
+	var err error
+	for _, v := range r.Results {
+		if v.Err != nil {
+			err = errors.Join(err, fmt.Errorf("%s: %w", v.Name, v.Err))
+		}
+	}
+	return err
+}
+
+// String returns the detailed report, one line per case and a summary at the end.
+func (r *SelfTestReport) String() string {
+	var b strings.Builder
+	var passed, failed, skipped int
+	for _, v := range r.Results {
+		switch {
+		case v.Err != nil:
+			failed++
+			fmt.Fprintf(&b, "FAIL %s: %s\n", v.Name, v.Err)
+		case v.Skipped:
+			skipped++
+			fmt.Fprintf(&b, "SKIP %s: %s\n", v.Name, v.Detail)
+		default:
+			passed++
+			fmt.Fprintf(&b, "PASS %s\n", v.Name)
+		}
+	}
+	fmt.Fprintf(&b, "passed: %d, failed: %d, skipped: %d\n", passed, failed, skipped)
+	return b.String()
+}
+
+// selfTestVector is a known-answer vector, the values are hex encoded.
+type selfTestVector struct {
+	name       string
+	method     method.MethodType
+	mode       mode.ModeType
+	key        string
+	iv         string
+	aad        string
+	plaintext  string
+	ciphertext string
+	tagSize    int
+}
+
+// selfTestVectors is the known-answer vectors from the standards. The block cipher vectors use the ECB mode
+// to encrypt a single block.
+var selfTestVectors = []selfTestVector{
+	{
+		name:       "AES-128 FIPS-197 C.1",
+		method:     method.AES,
+		mode:       mode.ECB,
+		key:        "000102030405060708090a0b0c0d0e0f",
+		plaintext:  "00112233445566778899aabbccddeeff",
+		ciphertext: "69c4e0d86a7b0430d8cdb78070b4c55a",
+	},
+	{
+		name:       "AES-192 FIPS-197 C.2",
+		method:     method.AES,
+		mode:       mode.ECB,
+		key:        "000102030405060708090a0b0c0d0e0f1011121314151617",
+		plaintext:  "00112233445566778899aabbccddeeff",
+		ciphertext: "dda97ca4864cdfe06eaf70a0ec0d7191",
+	},
+	{
+		name:       "AES-256 FIPS-197 C.3",
+		method:     method.AES,
+		mode:       mode.ECB,
+		key:        "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+		plaintext:  "00112233445566778899aabbccddeeff",
+		ciphertext: "8ea2b7ca516745bfeafc49904b496089",
+	},
+	{
+		name:       "SM4 GM/T 0002-2012 A.1",
+		method:     method.SM4,
+		mode:       mode.ECB,
+		key:        "0123456789abcdeffedcba9876543210",
+		plaintext:  "0123456789abcdeffedcba9876543210",
+		ciphertext: "681edf34d206965e86b3e94f536e4246",
+	},
+	{
+		name:       "CAST5 RFC 2144 B.1",
+		method:     method.CAST5,
+		mode:       mode.ECB,
+		key:        "0123456712345678234567893456789a",
+		plaintext:  "0123456789abcdef",
+		ciphertext: "238b4fe5847e44b2",
+	},
+	{
+		name:       "Twofish-128 zero key",
+		method:     method.Twofish,
+		mode:       mode.ECB,
+		key:        "00000000000000000000000000000000",
+		plaintext:  "00000000000000000000000000000000",
+		ciphertext: "9f589f5cf6122c32b6bfec2f2ae8c35a",
+	},
+	{
+		name:       "TEA zero key",
+		method:     method.TEA,
+		mode:       mode.ECB,
+		key:        "00000000000000000000000000000000",
+		plaintext:  "0000000000000000",
+		ciphertext: "41ea3a0a94baa940",
+	},
+	{
+		name:       "XTEA",
+		method:     method.XTEA,
+		mode:       mode.ECB,
+		key:        "000102030405060708090a0b0c0d0e0f",
+		plaintext:  "4142434445464748",
+		ciphertext: "497df3d072612cb5",
+	},
+	{
+		name:       "DES FIPS 46-3",
+		method:     method.DES,
+		mode:       mode.ECB,
+		key:        "133457799bbcdff1",
+		plaintext:  "0123456789abcdef",
+		ciphertext: "85e813540f0ab405",
+	},
+	{
+		name:       "3DES SP 800-67",
+		method:     method.TripleDES,
+		mode:       mode.ECB,
+		key:        "0123456789abcdef23456789abcdef01456789abcdef0123",
+		plaintext:  "5468652071756663",
+		ciphertext: "a826fd8ce53b855f",
+	},
+	{
+		name:       "Blowfish zero key",
+		method:     method.Blowfish,
+		mode:       mode.ECB,
+		key:        "0000000000000000",
+		plaintext:  "0000000000000000",
+		ciphertext: "4ef997456198dd78",
+	},
+	{
+		name:       "AES-128-CBC SP 800-38A F.2.1",
+		method:     method.AES,
+		mode:       mode.CBC,
+		key:        "2b7e151628aed2a6abf7158809cf4f3c",
+		iv:         "000102030405060708090a0b0c0d0e0f",
+		plaintext:  "6bc1bee22e409f96e93d7e117393172a",
+		ciphertext: "7649abac8119b246cee98e9b12e9197d",
+	},
+	{
+		name:       "AES-128-CFB SP 800-38A F.3.13",
+		method:     method.AES,
+		mode:       mode.CFB,
+		key:        "2b7e151628aed2a6abf7158809cf4f3c",
+		iv:         "000102030405060708090a0b0c0d0e0f",
+		plaintext:  "6bc1bee22e409f96e93d7e117393172a",
+		ciphertext: "3b3fd92eb72dad20333449f8e83cfb4a",
+	},
+	{
+		name:       "AES-128-OFB SP 800-38A F.4.1",
+		method:     method.AES,
+		mode:       mode.OFB,
+		key:        "2b7e151628aed2a6abf7158809cf4f3c",
+		iv:         "000102030405060708090a0b0c0d0e0f",
+		plaintext:  "6bc1bee22e409f96e93d7e117393172a",
+		ciphertext: "3b3fd92eb72dad20333449f8e83cfb4a",
+	},
+	{
+		name:       "AES-128-CTR SP 800-38A F.5.1",
+		method:     method.AES,
+		mode:       mode.CTR,
+		key:        "2b7e151628aed2a6abf7158809cf4f3c",
+		iv:         "f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff",
+		plaintext:  "6bc1bee22e409f96e93d7e117393172a",
+		ciphertext: "874d6191b620e3261bef6864990db6ce",
+	},
+	{
+		name:       "AES-128-GCM test case 2",
+		method:     method.AES,
+		mode:       mode.GCM,
+		key:        "00000000000000000000000000000000",
+		iv:         "000000000000000000000000",
+		plaintext:  "00000000000000000000000000000000",
+		ciphertext: "0388dace60b6a392f328c2b971b2fe78ab6e47d42cec13bdf53a67b21257bddf",
+	},
+	{
+		name:   "SM4-GCM RFC 8998 A.1",
+		method: method.SM4,
+		mode:   mode.GCM,
+		key:    "0123456789abcdeffedcba9876543210",
+		iv:     "00001234567800000000abcd",
+		aad:    "feedfacedeadbeeffeedfacedeadbeefabaddad2",
+		plaintext: "aaaaaaaaaaaaaaaabbbbbbbbbbbbbbbbccccccccccccccccdddddddddddddddd" +
+			"eeeeeeeeeeeeeeeeffffffffffffffffeeeeeeeeeeeeeeeeaaaaaaaaaaaaaaaa",
+		ciphertext: "17f399f08c67d5ee19d0dc9969c4bb7d5fd46fd3756489069157b282bb200735" +
+			"d82710ca5c22f0ccfa7cbf93d496ac15a56834cbcf98c397b4024a2691233b8d" +
+			"83de3541e4c2b58177e065a9bf7b62ec",
+	},
+	{
+		name:       "AES-128-CCM SP 800-38C C.1",
+		method:     method.AES,
+		mode:       mode.CCM,
+		key:        "404142434445464748494a4b4c4d4e4f",
+		iv:         "10111213141516",
+		aad:        "0001020304050607",
+		plaintext:  "20212223",
+		ciphertext: "7162015b4dac255d",
+		tagSize:    4,
+	},
+	{
+		name:   "SM4-CCM RFC 8998 A.2",
+		method: method.SM4,
+		mode:   mode.CCM,
+		key:    "0123456789abcdeffedcba9876543210",
+		iv:     "00001234567800000000abcd",
+		aad:    "feedfacedeadbeeffeedfacedeadbeefabaddad2",
+		plaintext: "aaaaaaaaaaaaaaaabbbbbbbbbbbbbbbbccccccccccccccccdddddddddddddddd" +
+			"eeeeeeeeeeeeeeeeffffffffffffffffeeeeeeeeeeeeeeeeaaaaaaaaaaaaaaaa",
+		ciphertext: "48af93501fa62adbcd414cce6034d895dda1bf8f132f042098661572e7483094" +
+			"fd12e518ce062c98acee28d95df4416bed31a2f04476c18bb40c84a74b97dc5b" +
+			"16842d4fa186f56ab33256971fa110f4",
+	},
+	{
+		name:       "AES-SIV RFC 5297 A.1",
+		method:     method.AES,
+		mode:       mode.SIV,
+		key:        "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff",
+		aad:        "101112131415161718191a1b1c1d1e1f2021222324252627",
+		plaintext:  "112233445566778899aabbccddee",
+		ciphertext: "85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c",
+	},
+	{
+		name:   "ChaCha20-Poly1305 RFC 8439 2.8.2",
+		method: method.ChaCha20Poly1305,
+		key:    "808182838485868788898a8b8c8d8e8f909192939495969798999a9b9c9d9e9f",
+		iv:     "070000004041424344454647",
+		aad:    "50515253c0c1c2c3c4c5c6c7",
+		plaintext: hex.EncodeToString([]byte("Ladies and Gentlemen of the class of '99: " +
+			"If I could offer you only one tip for the future, sunscreen would be it.")),
+		ciphertext: "d31a8d34648e60db7b86afbc53ef7ec2a4aded51296e08fea9e2b5a736ee62d6" +
+			"3dbea45e8ca9671282fafb69da92728b1a71de0a9e060b2905d6a5b67ecd3b36" +
+			"92ddbd7f2d778b8c9803aee328091b58fab324e4fad675945585808b4831d7bc" +
+			"3ff4def08e4b7a9de576d26586cec64b6116" +
+			"1ae10b594f09e26a7e902ecbd0600691",
+	},
+	{
+		name:   "XChaCha20-Poly1305 draft-irtf-cfrg-xchacha A.3.1",
+		method: method.XChaCha20Poly1305,
+		key:    "808182838485868788898a8b8c8d8e8f909192939495969798999a9b9c9d9e9f",
+		iv:     "404142434445464748494a4b4c4d4e4f5051525354555657",
+		aad:    "50515253c0c1c2c3c4c5c6c7",
+		plaintext: hex.EncodeToString([]byte("Ladies and Gentlemen of the class of '99: " +
+			"If I could offer you only one tip for the future, sunscreen would be it.")),
+		ciphertext: "bd6d179d3e83d43b9576579493c0e939572a1700252bfaccbed2902c21396cbb" +
+			"731c7f1b0b4aa6440bf3a82f4eda7e39ae64c6708c54c216cb96b72e1213b452" +
+			"2f8c9ba40db5d945b11b69b982c1bb9e3f3fac2bc369488f76b2383565d3fff9" +
+			"21f9664c97637da9768812f615c68b13b52e" +
+			"c0875924c1c7987947deafd8780acf49",
+	},
+}
+
+// selfTestPaddingVector is a known-answer vector of the padding, the values are hex encoded.
+type selfTestPaddingVector struct {
+	padding padding.PaddingType
+	data    string
+	want    string
+}
+
+// selfTestPaddingVectors is the known-answer vectors of the paddings with 8 bytes block, ISO10126 is checked by
+// the size and the last byte because its padding bytes are random.
+var selfTestPaddingVectors = []selfTestPaddingVector{
+	{padding: padding.No, data: "6162636465666768", want: "6162636465666768"},
+	{padding: padding.PKCS5, data: "616263", want: "6162630505050505"},
+	{padding: padding.PKCS7, data: "6162636465666768", want: "61626364656667680808080808080808"},
+	{padding: padding.Zero, data: "616263", want: "6162630000000000"},
+	{padding: padding.ISO97971, data: "616263", want: "6162638000000000"},
+	{padding: padding.ANSIX923, data: "616263", want: "6162630000000005"},
+	{padding: padding.ISO10126, data: "616263", want: "616263xxxxxxxx05"},
+}
+
+// The built-in methods, modes and paddings covered by the round trips of SelfTest.
+var (
+	selfTestMethods = []method.MethodType{
+		method.AES, method.CAST5, method.SM4, method.Twofish, method.TEA, method.XTEA,
+		method.ChaCha20Poly1305, method.XChaCha20Poly1305, method.DES, method.TripleDES, method.Blowfish,
+	}
+	selfTestModes = []mode.ModeType{
+		mode.CBC, mode.CFB, mode.OFB, mode.CTR, mode.GCM, mode.ECB, mode.CCM, mode.SIV,
+	}
+	selfTestPaddings = []padding.PaddingType{
+		padding.ISO97971, padding.No, padding.PKCS5, padding.PKCS7, padding.Zero, padding.ANSIX923, padding.ISO10126,
+	}
+	selfTestMACs = []mac.MACType{mac.HMACSHA256, mac.HMACSM3}
+)
+
+// SelfTest runs the embedded NIST and GM/T known-answer vectors of all methods and modes, the known-answer vectors
+// of all paddings and SM3, and the encryption and decryption round trips of every method, mode and padding
+// combination. It is designed to be run at startup, so that a broken implementation is found before it is used.
+// The unsupported combinations such as DES-GCM are skipped.
+func SelfTest() *SelfTestReport {
+	report := &SelfTestReport{}
+
+	for _, v := range selfTestVectors {
+		report.Results = append(report.Results, SelfTestResult{Name: "KAT " + v.name, Err: v.run()})
+	}
+
+	for _, v := range selfTestPaddingVectors {
+		report.Results = append(report.Results, SelfTestResult{Name: "KAT padding " + v.padding.String(), Err: v.run()})
+	}
+
+	report.Results = append(report.Results, SelfTestResult{Name: "KAT SM3 GM/T 0004-2012 A.1", Err: selfTestSM3()})
+
+	for _, m := range selfTestMethods {
+		// the mode is ignored by ChaCha20-Poly1305
+		modes := selfTestModes
+		if isChaCha(m) {
+			modes = []mode.ModeType{0}
+		}
+
+		for _, md := range modes {
+			for _, p := range selfTestPaddings {
+				report.Results = append(report.Results, selfTestRoundTrip(m, md, p, 0))
+			}
+		}
+	}
+
+	for _, v := range selfTestMACs {
+		for _, md := range []mode.ModeType{mode.CBC, mode.CTR} {
+			report.Results = append(report.Results, selfTestRoundTrip(method.AES, md, padding.PKCS7, v))
+		}
+	}
+
+	return report
+}
+
+// run encrypts the plaintext and decrypts the ciphertext of the vector with CryptoS.
+func (v selfTestVector) run() error {
+	var values [5][]byte
+	for i, value := range []string{v.key, v.iv, v.aad, v.plaintext, v.ciphertext} {
+		var err error
+		if values[i], err = hex.DecodeString(value); err != nil {
+			return err
+		}
+	}
+	key, iv, aad, plaintext, ciphertext := values[0], values[1], values[2], values[3], values[4]
+
+	newCryptoS := func() *CryptoS {
+		s := NewCryptoS()
+		s.WithMethod(v.method).WithMode(v.mode).WithPadding(padding.No).WithKey(key).WithIV(iv).
+			WithAdditionalData(aad).WithTagSize(v.tagSize).AllowECB().AllowInsecure()
+		if s.isAEAD() {
+			s.WithNonceSize(len(iv))
+		}
+		return &s
+	}
+
+	result, err := newCryptoS().InputFromBytes(plaintext).Encrypt().ToBytes()
+	if err != nil {
+		return fmt.Errorf("failed to encrypt, error:%w", err)
+	}
+	if !bytes.Equal(result, ciphertext) {
+		return fmt.Errorf("the ciphertext is %x, want %x", result, ciphertext)
+	}
+
+	result, err = newCryptoS().InputFromBytes(ciphertext).Decrypt().ToBytes()
+	if err != nil {
+		return fmt.Errorf("failed to decrypt, error:%w", err)
+	}
+	if !bytes.Equal(result, plaintext) {
+		return fmt.Errorf("the plaintext is %x, want %x", result, plaintext)
+	}
+
+	return nil
+}
+
+// run pads the data and depads the result of the vector.
+func (v selfTestPaddingVector) run() error {
+	data, err := hex.DecodeString(v.data)
+	if err != nil {
+		return err
+	}
+
+	result, err := padding.Padding(bytes.Clone(data), v.padding, 8)
+	if err != nil {
+		return fmt.Errorf("failed to pad, error:%w", err)
+	}
+
+	// the random bytes are marked as xx
+	got := hex.EncodeToString(result)
+	if len(got) != len(v.want) {
+		return fmt.Errorf("the padded data is %s, want %s", got, v.want)
+	}
+	for i := 0; i < len(got); i += 2 {
+		if v.want[i:i+2] != "xx" && v.want[i:i+2] != got[i:i+2] {
+			return fmt.Errorf("the padded data is %s, want %s", got, v.want)
+		}
+	}
+
+	result, err = padding.DePadding(result, v.padding, 8)
+	if err != nil {
+		return fmt.Errorf("failed to depad, error:%w", err)
+	}
+	if !bytes.Equal(result, data) {
+		return fmt.Errorf("the depadded data is %x, want %x", result, data)
+	}
+
+	return nil
+}
+
+// selfTestSM3 checks SM3 which is used by HMAC-SM3.
+func selfTestSM3() error {
+	h := sm3.New()
+	h.Write([]byte("abc"))
+	if result := hex.EncodeToString(h.Sum(nil)); result !=
+		"66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0" {
+		return fmt.Errorf("the digest is %s", result)
+	}
+	return nil
+}
+
+// selfTestRoundTrip encrypts and decrypts the data of several lengths with the combination, the combinations which
+// are not available are skipped.
+func selfTestRoundTrip(m method.MethodType, md mode.ModeType, p padding.PaddingType, macType mac.MACType) SelfTestResult {
+	s := NewCryptoS()
+	s.WithMethod(m).WithMode(md).WithPadding(p).WithMAC(macType).AllowECB().AllowInsecure()
+
+	result := SelfTestResult{Name: "round trip " + m.String()}
+	if !isChaCha(m) {
+		result.Name += "/" + md.String()
+	}
+	result.Name += "/" + p.String()
+	if macType != 0 {
+		result.Name += " with " + macType.String()
+	}
+
+	if err := checkTransformation(m, md, p); err != nil {
+		result.Skipped, result.Detail = true, err.Error()
+		return result
+	}
+
+	sizes := s.keySizes()
+	keySize := s.keySize()
+	if len(sizes) > 0 {
+		keySize = sizes[len(sizes)-1]
+	}
+
+	key := make([]byte, keySize)
+	for i := range key {
+		key[i] = byte(i*7 + 1)
+	}
+
+	// only the combinations rejected by checkTransformation are not available, the other errors are failures
+	s.WithKey(key).WithRandomIV().WithAdditionalData([]byte("knife"))
+	if err := s.validateConfig(); err != nil {
+		result.Err = fmt.Errorf("failed to validate, error:%w", err)
+		return result
+	}
+
+	for _, length := range selfTestLengths(&s) {
+		data := make([]byte, length)
+		for i := range data {
+			data[i] = byte(i%251 + 1)
+		}
+
+		// the empty input data is rejected by Encrypt, it is only used by the stream
+		if length > 0 {
+			if err := selfTestCrypt(&s, data); err != nil {
+				result.Err = fmt.Errorf("failed with %d bytes, error:%w", length, err)
+				return result
+			}
+		}
+
+		if !s.isAEAD() && s.MAC == 0 {
+			if err := selfTestStream(&s, data); err != nil {
+				result.Err = fmt.Errorf("failed to stream %d bytes, error:%w", length, err)
+				return result
+			}
+		}
+	}
+
+	return result
+}
+
+// selfTestLengths returns the lengths of the round trip data around the block size. The CBC and ECB modes without
+// padding only use the lengths aligned to the blocks.
+func selfTestLengths(s *CryptoS) []int {
+	blockSize := s.blockSize()
+	if blockSize == 0 {
+		blockSize = 16
+	}
+
+	if !s.isAEAD() && (s.Mode == mode.CBC || s.Mode == mode.ECB) && (s.Padding == 0 || s.Padding == padding.No) {
+		return []int{0, blockSize, 2 * blockSize}
+	}
+
+	return []int{0, blockSize - 1, blockSize, blockSize + 1, 2*blockSize + 1}
+}
+
+// selfTestCrypt encrypts and decrypts the data by Encrypt and Decrypt.
+func selfTestCrypt(s *CryptoS, data []byte) error {
+	ciphertext, err := s.InputFromBytes(bytes.Clone(data)).Encrypt().ToBytes()
+	if err != nil {
+		return fmt.Errorf("failed to encrypt, error:%w", err)
+	}
+
+	plaintext, err := s.InputFromBytes(ciphertext).Decrypt().ToBytes()
+	if err != nil {
+		return fmt.Errorf("failed to decrypt, error:%w", err)
+	}
+
+	if !bytes.Equal(plaintext, data) {
+		return fmt.Errorf("the plaintext is %x, want %x", plaintext, data)
+	}
+
+	return nil
+}
+
+// selfTestStream encrypts and decrypts the data by NewEncryptWriter and NewDecryptReader.
+func selfTestStream(s *CryptoS, data []byte) error {
+	var buf bytes.Buffer
+	w, err := s.NewEncryptWriter(&buf)
+	if err != nil {
+		return fmt.Errorf("failed to create writer, error:%w", err)
+	}
+
+	if _, err = w.Write(data); err != nil {
+		return fmt.Errorf("failed to encrypt, error:%w", err)
+	}
+	if err = w.Close(); err != nil {
+		return fmt.Errorf("failed to encrypt, error:%w", err)
+	}
+
+	r, err := s.NewDecryptReader(&buf)
+	if err != nil {
+		return fmt.Errorf("failed to create reader, error:%w", err)
+	}
+
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt, error:%w", err)
+	}
+
+	if !bytes.Equal(plaintext, data) {
+		return fmt.Errorf("the plaintext is %x, want %x", plaintext, data)
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"crypto/cipher"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/suyuan32/knife/cryptox/symmetric/method"
+	"github.com/suyuan32/knife/cryptox/symmetric/mode"
+	"github.com/suyuan32/knife/cryptox/symmetric/padding"
+)
+
+func TestSelfTest(t *testing.T) {
+	report := SelfTest()
+	assert.True(t, report.Passed(), report.String())
+	assert.Nil(t, report.Err())
+
+	// every combination is reported
+	combinations := 0
+	for _, m := range selfTestMethods {
+		if isChaCha(m) {
+			combinations += len(selfTestPaddings)
+		} else {
+			combinations += len(selfTestModes) * len(selfTestPaddings)
+		}
+	}
+	assert.Equal(t, len(selfTestVectors)+len(selfTestPaddingVectors)+1+combinations+len(selfTestMACs)*2,
+		len(report.Results))
+
+	result := report.String()
+	assert.Contains(t, result, "PASS KAT SM4 GM/T 0002-2012 A.1\n")
+	assert.Contains(t, result, "PASS round trip SM4/CBC/PKCS7\n")
+	assert.Contains(t, result, "PASS round trip ChaCha20-Poly1305/None\n")
+	assert.Contains(t, result, "SKIP round trip DES/GCM/None: the GCM mode requires a 16 bytes block cipher")
+	assert.Contains(t, result, ", failed: 0, ")
+}
+
+func TestSelfTest_Failure(t *testing.T) {
+	vector := selfTestVectors[3]
+	vector.ciphertext = "681edf34d206965e86b3e94f536e4247"
+	assert.EqualError(t, vector.run(), "the ciphertext is 681edf34d206965e86b3e94f536e4246, "+
+		"want 681edf34d206965e86b3e94f536e4247")
+
+	vector.key = "0123"
+	assert.NotNil(t, vector.run())
+
+	vector.key = "zz"
+	assert.NotNil(t, vector.run())
+
+	paddingVector := selfTestPaddingVector{padding: padding.PKCS7, data: "616263", want: "6162630404040404"}
+	assert.EqualError(t, paddingVector.run(), "the padded data is 6162630505050505, want 6162630404040404")
+
+	paddingVector.want = "61626305050505"
+	assert.NotNil(t, paddingVector.run())
+
+	report := &SelfTestReport{Results: []SelfTestResult{
+		{Name: "KAT broken", Err: errors.New("the ciphertext is wrong")},
+		{Name: "round trip skipped", Skipped: true, Detail: "not supported"},
+		selfTestRoundTrip(method.AES, mode.CTR, padding.No, 0),
+	}}
+	assert.False(t, report.Passed())
+	assert.EqualError(t, report.Err(), "KAT broken: the ciphertext is wrong")
+	assert.Equal(t, "FAIL KAT broken: the ciphertext is wrong\nSKIP round trip skipped: not supported\n"+
+		"PASS round trip AES/CTR/None\npassed: 1, failed: 1, skipped: 1\n", report.String())
+
+	// the errors of available combinations are failures instead of skips
+	m := method.FirstRegistered + 75
+	assert.Nil(t, method.Register(m, "TestBrokenCipher", func(key []byte) (cipher.Block, error) {
+		return nil, errors.New("the cipher is broken")
+	}))
+	result := selfTestRoundTrip(m, mode.CBC, padding.PKCS7, 0)
+	assert.False(t, result.Skipped)
+	assert.NotNil(t, result.Err)
+}
@@ -0,0 +1,200 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"errors"
+)
+
+// sivBlockSize is the block size required by the SIV mode.
+const sivBlockSize = 16
+
+// siv implements the SIV mode (Synthetic Initialization Vector) defined in RFC 5297. The synthetic IV is
+// computed by S2V from the associated data, nonce and plaintext, so the same input always produces the same
+// ciphertext and reusing a nonce only reveals whether the messages are equal.
+type siv struct {
+	macBlock  cipher.Block
+	ctrBlock  cipher.Block
+	nonceSize int
+}
+
+// newSIV returns the SIV mode with the MAC block and CTR block created from the two halves of the key.
+// The nonce is optional, it is not used if the nonce size is zero.
+func newSIV(macBlock, ctrBlock cipher.Block, nonceSize int) (cipher.AEAD, error) {
+	if macBlock.BlockSize() != sivBlockSize || ctrBlock.BlockSize() != sivBlockSize {
+		return nil, errors.New("the SIV mode requires a 16 bytes block cipher")
+	}
+
+	if nonceSize < 0 {
+		return nil, errors.New("the nonce size of SIV mode cannot be negative")
+	}
+
+	return &siv{macBlock: macBlock, ctrBlock: ctrBlock, nonceSize: nonceSize}, nil
+}
+
+// NonceSize returns the size of the nonce.
+func (s *siv) NonceSize() int {
+	return s.nonceSize
+}
+
+// Overhead returns the size of the synthetic IV.
+func (s *siv) Overhead() int {
+	return sivBlockSize
+}
+
+// Seal encrypts and authenticates the plaintext, authenticates the additional data and appends the result to dst.
+// The synthetic IV is prepended to the ciphertext.
+func (s *siv) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != s.nonceSize {
+		panic("crypto/cipher: incorrect nonce length given to SIV")
+	}
+
+	return s.seal(dst, plaintext, s.components(nonce, additionalData)...)
+}
+
+// Open decrypts and authenticates the ciphertext, authenticates the additional data and, if successful,
+// appends the resulting plaintext to dst.
+func (s *siv) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != s.nonceSize {
+		panic("crypto/cipher: incorrect nonce length given to SIV")
+	}
+
+	return s.open(dst, ciphertext, s.components(nonce, additionalData)...)
+}
+
+// components returns the associated data components of S2V, the additional data and the nonce are only
+// included if they are not empty.
+func (s *siv) components(nonce, additionalData []byte) [][]byte {
+	var result [][]byte
+	if len(additionalData) > 0 {
+		result = append(result, additionalData)
+	}
+	if len(nonce) > 0 {
+		result = append(result, nonce)
+	}
+	return result
+}
+
+// seal encrypts the plaintext with the associated data components.
+func (s *siv) seal(dst, plaintext []byte, components ...[]byte) []byte {
+	v := s.s2v(plaintext, components)
+
+	ret, out := sliceForAppend(dst, sivBlockSize+len(plaintext))
+	copy(out, v)
+	s.crypt(v, out[sivBlockSize:], plaintext)
+
+	return ret
+}
+
+// open decrypts the ciphertext with the associated data components.
+func (s *siv) open(dst, ciphertext []byte, components ...[]byte) ([]byte, error) {
+	if len(ciphertext) < sivBlockSize {
+		return nil, ErrAuthenticationFailed
+	}
+
+	v := ciphertext[:sivBlockSize]
+	ciphertext = ciphertext[sivBlockSize:]
+
+	ret, out := sliceForAppend(dst, len(ciphertext))
+	s.crypt(v, out, ciphertext)
+
+	if subtle.ConstantTimeCompare(s.s2v(out, components), v) != 1 {
+		for i := range out {
+			out[i] = 0
+		}
+		return nil, ErrAuthenticationFailed
+	}
+
+	return ret, nil
+}
+
+// crypt encrypts or decrypts src in counter mode with the synthetic IV whose 31st and 63rd bits are cleared.
+func (s *siv) crypt(v, dst, src []byte) {
+	q := make([]byte, sivBlockSize)
+	copy(q, v)
+	q[8] &= 0x7f
+	q[12] &= 0x7f
+
+	cipher.NewCTR(s.ctrBlock, q).XORKeyStream(dst, src)
+}
+
+// s2v computes the synthetic IV from the associated data components and plaintext.
+func (s *siv) s2v(plaintext []byte, components [][]byte) []byte {
+	d := cmac(s.macBlock, make([]byte, sivBlockSize))
+	for _, v := range components {
+		dbl(d)
+		subtle.XORBytes(d, d, cmac(s.macBlock, v))
+	}
+
+	var t []byte
+	if len(plaintext) >= sivBlockSize {
+		t = append([]byte{}, plaintext...)
+		subtle.XORBytes(t[len(t)-sivBlockSize:], t[len(t)-sivBlockSize:], d)
+	} else {
+		dbl(d)
+		t = make([]byte, sivBlockSize)
+		copy(t, plaintext)
+		t[len(plaintext)] = 0x80
+		subtle.XORBytes(t, t, d)
+	}
+
+	return cmac(s.macBlock, t)
+}
+
+// cmac returns the CMAC of the data defined in NIST SP 800-38B with a 16 bytes block cipher.
+func cmac(block cipher.Block, data []byte) []byte {
+	k1 := make([]byte, sivBlockSize)
+	block.Encrypt(k1, k1)
+	dbl(k1)
+
+	last := make([]byte, sivBlockSize)
+	if n := len(data); n > 0 && n%sivBlockSize == 0 {
+		copy(last, data[n-sivBlockSize:])
+		subtle.XORBytes(last, last, k1)
+		data = data[:n-sivBlockSize]
+	} else {
+		k2 := append([]byte{}, k1...)
+		dbl(k2)
+
+		rest := data[n-n%sivBlockSize:]
+		copy(last, rest)
+		last[len(rest)] = 0x80
+		subtle.XORBytes(last, last, k2)
+		data = data[:n-len(rest)]
+	}
+
+	mac := make([]byte, sivBlockSize)
+	for len(data) > 0 {
+		subtle.XORBytes(mac, mac, data[:sivBlockSize])
+		block.Encrypt(mac, mac)
+		data = data[sivBlockSize:]
+	}
+
+	subtle.XORBytes(mac, mac, last)
+	block.Encrypt(mac, mac)
+
+	return mac
+}
+
+// dbl multiplies the 16 bytes block by x in GF(2^128) in place.
+func dbl(b []byte) {
+	carry := b[0] >> 7
+	for i := 0; i < len(b)-1; i++ {
+		b[i] = b[i]<<1 | b[i+1]>>7
+	}
+	b[len(b)-1] = b[len(b)-1]<<1 ^ 0x87&-carry
+}
@@ -0,0 +1,140 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"crypto/aes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/suyuan32/knife/cryptox/symmetric/mode"
+)
+
+func mustDecodeHex(s string) []byte {
+	result, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+func TestCMAC(t *testing.T) {
+	block, err := aes.NewCipher(mustDecodeHex("2b7e151628aed2a6abf7158809cf4f3c"))
+	assert.Nil(t, err)
+
+	// NIST SP 800-38B examples
+	assert.Equal(t, "bb1d6929e95937287fa37d129b756746", hex.EncodeToString(cmac(block, nil)))
+	assert.Equal(t, "070a16b46b4d4144f79bdd9dd04a287c",
+		hex.EncodeToString(cmac(block, mustDecodeHex("6bc1bee22e409f96e93d7e117393172a"))))
+	assert.Equal(t, "dfa66747de9ae63030ca32611497c827", hex.EncodeToString(cmac(block, mustDecodeHex(
+		"6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e5130c81c46a35ce411"))))
+}
+
+func TestCryptoS_SIV_KnownAnswer(t *testing.T) {
+	// RFC 5297 A.1 deterministic authenticated encryption
+	c := NewCryptoS()
+	result, err := c.InputFromHexString("112233445566778899aabbccddee").
+		WithMode(mode.SIV).
+		KeyFromHexString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff").
+		WithAdditionalData(mustDecodeHex("101112131415161718191a1b1c1d1e1f2021222324252627")).
+		Encrypt().
+		ToHexString()
+	assert.Nil(t, err)
+	assert.Equal(t, "85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c", result)
+
+	c = NewCryptoS()
+	result, err = c.InputFromHexString("85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c").
+		WithMode(mode.SIV).
+		KeyFromHexString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff").
+		WithAdditionalData(mustDecodeHex("101112131415161718191a1b1c1d1e1f2021222324252627")).
+		Decrypt().
+		ToHexString()
+	assert.Nil(t, err)
+	assert.Equal(t, "112233445566778899aabbccddee", result)
+
+	// RFC 5297 A.2 nonce-based authenticated encryption with multiple associated data
+	key := mustDecodeHex("7f7e7d7c7b7a79787776757473727170404142434445464748494a4b4c4d4e4f")
+	macBlock, _ := aes.NewCipher(key[:16])
+	ctrBlock, _ := aes.NewCipher(key[16:])
+	aead, err := newSIV(macBlock, ctrBlock, 0)
+	assert.Nil(t, err)
+
+	components := [][]byte{
+		mustDecodeHex("00112233445566778899aabbccddeeffdeaddadadeaddadaffeeddccbbaa99887766554433221100"),
+		mustDecodeHex("102030405060708090a0"),
+		mustDecodeHex("09f911029d74e35bd84156c5635688c0"),
+	}
+	plaintext := mustDecodeHex("7468697320697320736f6d6520706c61696e7465787420746f20656e6372797074207573696e67205349562d414553")
+	want := "7bdb6e3b432667eb06f4d14bff2fbd0fcb900f2fddbe404326601965c889bf17" +
+		"dba77ceb094fa663b7a3f748ba8af829ea64ad544a272e9c485b62a3fd5c0d"
+
+	ciphertext := aead.(*siv).seal(nil, plaintext, components...)
+	assert.Equal(t, want, hex.EncodeToString(ciphertext))
+
+	opened, err := aead.(*siv).open(nil, ciphertext, components...)
+	assert.Nil(t, err)
+	assert.Equal(t, plaintext, opened)
+}
+
+func TestCryptoS_SIV(t *testing.T) {
+	key := mustDecodeHex("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+
+	c := NewCryptoS()
+	built, err := c.WithMode(mode.SIV).WithKey(key).WithAdditionalData([]byte("column")).Build()
+	assert.Nil(t, err)
+
+	// deterministic
+	first, err := built.Encrypt([]byte("alice@example.com"))
+	assert.Nil(t, err)
+	second, err := built.Encrypt([]byte("alice@example.com"))
+	assert.Nil(t, err)
+	assert.Equal(t, first, second)
+
+	other, err := built.Encrypt([]byte("bob@example.com"))
+	assert.Nil(t, err)
+	assert.NotEqual(t, first, other)
+
+	result, err := built.Decrypt(first)
+	assert.Nil(t, err)
+	assert.Equal(t, "alice@example.com", string(result))
+
+	first[len(first)-1] ^= 1
+	_, err = built.Decrypt(first)
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed))
+
+	// the nonce makes the output of the same plaintext different
+	c = NewCryptoS()
+	built, err = c.WithMode(mode.SIV).WithNonceSize(16).WithRandomIV().WithKey(key[:32]).Build()
+	assert.Nil(t, err)
+
+	first, err = built.Encrypt([]byte("hello"))
+	assert.Nil(t, err)
+	second, err = built.Encrypt([]byte("hello"))
+	assert.Nil(t, err)
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, 16+16+5, len(first))
+
+	result, err = built.Decrypt(second)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", string(result))
+
+	// the key must be double length
+	c = NewCryptoS()
+	_, err = c.WithMode(mode.SIV).WithKey(key[:16]).Build()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,290 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/suyuan32/knife/cryptox/symmetric/mode"
+	"github.com/suyuan32/knife/cryptox/symmetric/padding"
+)
+
+// streamBufferSize is the size of the buffer used to read ciphertext in the decrypt reader.
+const streamBufferSize = 32 * 1024
+
+// NewEncryptWriter returns a writer which encrypts the data written to it and writes the ciphertext to w.
+// CBC and ECB modes pad the final block when the writer is closed, so Close must be called after writing.
+// CFB, OFB and CTR modes are used as plain streams without padding. If w is an io.Closer, it is closed by Close.
+// If RandomIV is set, the random IV is written to w first.
+func (s *CryptoS) NewEncryptWriter(w io.Writer) (io.WriteCloser, error) {
+	var iv []byte
+	block, err := s.newStreamCipher(func(size int) error {
+		if err := s.generateIV(size); err != nil {
+			return err
+		}
+		iv = s.IV
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(iv) > 0 {
+		if _, err = w.Write(iv); err != nil {
+			return nil, err
+		}
+	}
+
+	switch s.Mode {
+	case mode.CBC:
+		return &blockWriter{w: w, mode: cipher.NewCBCEncrypter(block, s.IV), padding: s.paddingType()}, nil
+	case mode.ECB:
+		return &blockWriter{w: w, mode: newECBEncrypter(block), padding: s.paddingType()}, nil
+	case mode.CFB:
+		return &cipher.StreamWriter{S: cipher.NewCFBEncrypter(block, s.IV), W: w}, nil
+	case mode.OFB:
+		return &cipher.StreamWriter{S: cipher.NewOFB(block, s.IV), W: w}, nil
+	case mode.CTR:
+		return &cipher.StreamWriter{S: cipher.NewCTR(block, s.IV), W: w}, nil
+	}
+
+	return nil, errors.New("the mode is not supported in stream")
+}
+
+// NewDecryptReader returns a reader which decrypts the ciphertext read from r.
+// CBC and ECB modes remove the padding of the final block, CFB, OFB and CTR modes are used as plain streams.
+// If RandomIV is set, the IV is read from r first.
+func (s *CryptoS) NewDecryptReader(r io.Reader) (io.Reader, error) {
+	block, err := s.newStreamCipher(func(size int) error {
+		iv := make([]byte, size)
+		if _, err := io.ReadFull(r, iv); err != nil {
+			return fmt.Errorf("failed to read IV, error:%s", err)
+		}
+		s.IV = iv
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch s.Mode {
+	case mode.CBC:
+		return &blockReader{r: r, mode: cipher.NewCBCDecrypter(block, s.IV), padding: s.paddingType()}, nil
+	case mode.ECB:
+		return &blockReader{r: r, mode: newECBDecrypter(block), padding: s.paddingType()}, nil
+	case mode.CFB:
+		return &cipher.StreamReader{S: cipher.NewCFBDecrypter(block, s.IV), R: r}, nil
+	case mode.OFB:
+		return &cipher.StreamReader{S: cipher.NewOFB(block, s.IV), R: r}, nil
+	case mode.CTR:
+		return &cipher.StreamReader{S: cipher.NewCTR(block, s.IV), R: r}, nil
+	}
+
+	return nil, errors.New("the mode is not supported in stream")
+}
+
+// newStreamCipher creates and validates the cipher block for stream encryption and decryption,
+// randomIV is called with the IV size to set the IV if RandomIV is set.
+func (s *CryptoS) newStreamCipher(randomIV func(size int) error) (block cipher.Block, err error) {
+	s.operate(func() { block, err = s.streamCipher(randomIV) })
+	return block, err
+}
+
+// streamCipher creates and validates the cipher block in the operation started by newStreamCipher.
+func (s *CryptoS) streamCipher(randomIV func(size int) error) (cipher.Block, error) {
+	if s.isAEAD() {
+		return nil, errors.New("the AEAD mode is not supported in stream")
+	}
+
+	if s.MAC != 0 {
+		return nil, errors.New("the encrypt-then-MAC is not supported in stream")
+	}
+
+	if s.RandomIV {
+		if size := s.ivSize(s.blockSize()); size > 0 {
+			if err := randomIV(size); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	err := s.Validate(s.blockSize())
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate data, error:%w", err)
+	}
+
+	block, err := s.NewCipher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher from the data, error:%s", err)
+	}
+
+	return block, nil
+}
+
+// blockWriter encrypts full blocks and keeps the final block until it is closed.
+type blockWriter struct {
+	w       io.Writer
+	mode    cipher.BlockMode
+	padding padding.PaddingType
+	buf     []byte
+	closed  bool
+}
+
+// Write encrypts all the buffered blocks except the final one and writes them to the underlying writer.
+func (b *blockWriter) Write(p []byte) (int, error) {
+	if b.closed {
+		return 0, errors.New("the writer is closed")
+	}
+
+	b.buf = append(b.buf, p...)
+	if len(b.buf) == 0 {
+		return len(p), nil
+	}
+
+	// the final block (full or partial) is kept for padding
+	n := len(b.buf) - ((len(b.buf)-1)%b.mode.BlockSize() + 1)
+	if n == 0 {
+		return len(p), nil
+	}
+
+	out := make([]byte, n)
+	b.mode.CryptBlocks(out, b.buf[:n])
+	b.buf = append(b.buf[:0], b.buf[n:]...)
+
+	if _, err := b.w.Write(out); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Close pads and encrypts the final block, then closes the underlying writer if it is an io.Closer.
+// A full padding block is written for the empty stream unless the padding is No.
+func (b *blockWriter) Close() error {
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+
+	paddingData, err := padding.Padding(b.buf, b.padding, b.mode.BlockSize())
+	if err != nil {
+		return fmt.Errorf("failed to pad data, error:%s", err)
+	}
+
+	if len(paddingData) == 0 && b.padding != padding.No {
+		return errors.New("the padding must add at least one byte")
+	}
+
+	if len(paddingData)%b.mode.BlockSize() != 0 {
+		return errors.New("the data size needs to be an integer multiple of block size")
+	}
+
+	out := make([]byte, len(paddingData))
+	b.mode.CryptBlocks(out, paddingData)
+	b.buf = nil
+
+	if _, err := b.w.Write(out); err != nil {
+		return err
+	}
+
+	if c, ok := b.w.(io.Closer); ok {
+		return c.Close()
+	}
+
+	return nil
+}
+
+// blockReader decrypts full blocks and removes the padding of the final block at the end of the stream.
+type blockReader struct {
+	r       io.Reader
+	mode    cipher.BlockMode
+	padding padding.PaddingType
+	buf     []byte
+	in      []byte
+	out     []byte
+	err     error
+}
+
+// Read reads the decrypted data into p.
+func (b *blockReader) Read(p []byte) (int, error) {
+	for len(b.out) == 0 {
+		if b.err != nil {
+			return 0, b.err
+		}
+		b.fill()
+	}
+
+	n := copy(p, b.out)
+	b.out = b.out[n:]
+
+	return n, nil
+}
+
+// fill reads ciphertext from the underlying reader and decrypts all full blocks except the final one.
+func (b *blockReader) fill() {
+	if b.buf == nil {
+		b.buf = make([]byte, streamBufferSize)
+	}
+
+	n, err := b.r.Read(b.buf)
+	b.in = append(b.in, b.buf[:n]...)
+
+	blockSize := b.mode.BlockSize()
+
+	if err == io.EOF {
+		if len(b.in)%blockSize != 0 {
+			b.err = errors.New("the data size needs to be an integer multiple of block size")
+			return
+		}
+
+		// the padded stream has at least one block, the empty stream is truncated
+		if len(b.in) == 0 && b.padding != padding.No {
+			b.err = fmt.Errorf("failed to depad data, the final block is missing, error:%w", padding.ErrInvalidPadding)
+			return
+		}
+
+		result := make([]byte, len(b.in))
+		b.mode.CryptBlocks(result, b.in)
+		b.in = nil
+
+		dePaddingData, err := padding.DePadding(result, b.padding, blockSize)
+		if err != nil {
+			b.err = fmt.Errorf("failed to depad data, error:%w", err)
+			return
+		}
+
+		b.out = dePaddingData
+		b.err = io.EOF
+		return
+	}
+
+	if err != nil {
+		b.err = err
+		return
+	}
+
+	// the final block is kept until the end of the stream for depadding
+	size := len(b.in) - len(b.in)%blockSize - blockSize
+	if size <= 0 {
+		return
+	}
+
+	b.out = make([]byte, size)
+	b.mode.CryptBlocks(b.out, b.in[:size])
+	b.in = append(b.in[:0], b.in[size:]...)
+}
@@ -0,0 +1,249 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/suyuan32/knife/cryptox/symmetric/method"
+	"github.com/suyuan32/knife/cryptox/symmetric/mode"
+	"github.com/suyuan32/knife/cryptox/symmetric/padding"
+)
+
+func TestCryptoS_Stream(t *testing.T) {
+	for _, size := range []int{1, 15, 16, 17, 100, 40000, 100000} {
+		testStr := bytes.Repeat([]byte{'a', 'b', 'c'}, size)[:size]
+		for _, v := range []method.MethodType{method.AES, method.SM4, method.XTEA} {
+			for _, m := range []mode.ModeType{mode.CBC, mode.ECB, mode.CFB, mode.OFB, mode.CTR} {
+				p := padding.PKCS7
+				if m == mode.CFB || m == mode.OFB || m == mode.CTR {
+					p = padding.No
+				}
+
+				c := NewCryptoS()
+				c.WithMethod(v).
+					WithMode(m).
+					WithPadding(p).
+					WithIV(bytes.Repeat([]byte{'b'}, 16)).
+					WithKey(bytes.Repeat([]byte{'c'}, 16)).
+					AllowECB()
+				if v == method.XTEA {
+					c.WithIV(bytes.Repeat([]byte{'b'}, 8))
+				}
+
+				want, err := c.InputFromBytes(testStr).Encrypt().ToBytes()
+				assert.Nil(t, err)
+
+				var encrypted bytes.Buffer
+				w, err := c.NewEncryptWriter(&encrypted)
+				assert.Nil(t, err)
+
+				// write in small pieces
+				for i := 0; i < len(testStr); i += 7 {
+					end := i + 7
+					if end > len(testStr) {
+						end = len(testStr)
+					}
+					_, err = w.Write(testStr[i:end])
+					assert.Nil(t, err)
+				}
+				assert.Nil(t, w.Close())
+				assert.Equal(t, want, encrypted.Bytes())
+
+				r, err := c.NewDecryptReader(iotest.HalfReader(bytes.NewReader(encrypted.Bytes())))
+				assert.Nil(t, err)
+
+				decrypted, err := io.ReadAll(r)
+				assert.Nil(t, err)
+				assert.Equal(t, testStr, decrypted)
+			}
+		}
+	}
+}
+
+func TestCryptoS_Stream_RandomIV(t *testing.T) {
+	testStr := bytes.Repeat([]byte{'a'}, 1000)
+	for _, m := range []mode.ModeType{mode.CBC, mode.CTR, mode.ECB} {
+		p := padding.PKCS7
+		if m == mode.CTR {
+			p = padding.No
+		}
+
+		c := NewCryptoS()
+		c.WithMode(m).
+			WithPadding(p).
+			WithKey(bytes.Repeat([]byte{'c'}, 16)).
+			WithRandomIV().
+			AllowECB()
+
+		var encrypted bytes.Buffer
+		w, err := c.NewEncryptWriter(&encrypted)
+		assert.Nil(t, err)
+		_, err = w.Write(testStr)
+		assert.Nil(t, err)
+		assert.Nil(t, w.Close())
+
+		// the stream can be decrypted by Decrypt
+		d := NewCryptoS()
+		result, err := d.InputFromBytes(encrypted.Bytes()).
+			WithMode(m).
+			WithPadding(p).
+			WithKey(bytes.Repeat([]byte{'c'}, 16)).
+			WithRandomIV().
+			AllowECB().
+			Decrypt().
+			ToBytes()
+		assert.Nil(t, err)
+		assert.Equal(t, testStr, result)
+
+		r, err := c.NewDecryptReader(bytes.NewReader(encrypted.Bytes()))
+		assert.Nil(t, err)
+
+		decrypted, err := io.ReadAll(r)
+		assert.Nil(t, err)
+		assert.Equal(t, testStr, decrypted)
+	}
+
+	// the IV cannot be read
+	c := NewCryptoS()
+	c.WithKey(bytes.Repeat([]byte{'c'}, 16)).WithRandomIV()
+	_, err := c.NewDecryptReader(bytes.NewReader([]byte{1, 2, 3}))
+	assert.NotNil(t, err)
+}
+
+func TestCryptoS_Stream_Error(t *testing.T) {
+	c := NewCryptoS()
+	c.WithMode(mode.GCM).
+		WithIV(bytes.Repeat([]byte{'b'}, 12)).
+		WithKey(bytes.Repeat([]byte{'c'}, 16))
+
+	_, err := c.NewEncryptWriter(io.Discard)
+	assert.NotNil(t, err)
+
+	_, err = c.NewDecryptReader(bytes.NewReader(nil))
+	assert.NotNil(t, err)
+
+	// invalid IV
+	c = NewCryptoS()
+	c.WithIV([]byte{1}).WithKey(bytes.Repeat([]byte{'c'}, 16))
+
+	_, err = c.NewEncryptWriter(io.Discard)
+	assert.NotNil(t, err)
+
+	// data is not full blocks without padding
+	c = NewCryptoS()
+	c.WithPadding(padding.No).
+		WithIV(bytes.Repeat([]byte{'b'}, 16)).
+		WithKey(bytes.Repeat([]byte{'c'}, 16))
+
+	w, err := c.NewEncryptWriter(io.Discard)
+	assert.Nil(t, err)
+	_, err = w.Write([]byte("hello"))
+	assert.Nil(t, err)
+	assert.NotNil(t, w.Close())
+
+	r, err := c.NewDecryptReader(bytes.NewReader([]byte("hello")))
+	assert.Nil(t, err)
+	_, err = io.ReadAll(r)
+	assert.NotNil(t, err)
+}
+
+func TestCryptoS_Stream_Empty(t *testing.T) {
+	for _, m := range []mode.ModeType{mode.CBC, mode.ECB} {
+		for _, p := range []padding.PaddingType{padding.PKCS7, padding.ISO97971, padding.Zero} {
+			c := NewCryptoS()
+			c.WithMode(m).
+				WithPadding(p).
+				WithIV(bytes.Repeat([]byte{'b'}, 16)).
+				WithKey(bytes.Repeat([]byte{'c'}, 16)).
+				AllowECB()
+
+			var encrypted bytes.Buffer
+			w, err := c.NewEncryptWriter(&encrypted)
+			assert.Nil(t, err)
+			assert.Nil(t, w.Close())
+
+			// the empty stream is encrypted to a full padding block
+			assert.Len(t, encrypted.Bytes(), 16)
+
+			r, err := c.NewDecryptReader(bytes.NewReader(encrypted.Bytes()))
+			assert.Nil(t, err)
+
+			decrypted, err := io.ReadAll(r)
+			assert.Nil(t, err)
+			assert.Empty(t, decrypted)
+		}
+	}
+
+	// no padding block is written without padding
+	c := NewCryptoS()
+	c.WithPadding(padding.No).
+		WithIV(bytes.Repeat([]byte{'b'}, 16)).
+		WithKey(bytes.Repeat([]byte{'c'}, 16))
+
+	var encrypted bytes.Buffer
+	w, err := c.NewEncryptWriter(&encrypted)
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+	assert.Empty(t, encrypted.Bytes())
+
+	r, err := c.NewDecryptReader(bytes.NewReader(nil))
+	assert.Nil(t, err)
+	decrypted, err := io.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Empty(t, decrypted)
+}
+
+func TestCryptoS_Stream_Truncated(t *testing.T) {
+	for _, m := range []mode.ModeType{mode.CBC, mode.ECB} {
+		for _, p := range []padding.PaddingType{padding.PKCS7, padding.ISO97971, padding.Zero} {
+			c := NewCryptoS()
+			c.WithMode(m).
+				WithPadding(p).
+				WithIV(bytes.Repeat([]byte{'b'}, 16)).
+				WithKey(bytes.Repeat([]byte{'c'}, 16)).
+				AllowECB()
+
+			// the empty ciphertext has no padding block
+			r, err := c.NewDecryptReader(bytes.NewReader(nil))
+			assert.Nil(t, err)
+			_, err = io.ReadAll(r)
+			assert.ErrorIs(t, err, padding.ErrInvalidPadding)
+		}
+	}
+
+	// the stream is truncated to the IV
+	c := NewCryptoS()
+	c.WithPadding(padding.PKCS7).
+		WithKey(bytes.Repeat([]byte{'c'}, 16)).
+		WithRandomIV()
+
+	var encrypted bytes.Buffer
+	w, err := c.NewEncryptWriter(&encrypted)
+	assert.Nil(t, err)
+	_, err = w.Write([]byte("hello"))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+
+	r, err := c.NewDecryptReader(bytes.NewReader(encrypted.Bytes()[:16]))
+	assert.Nil(t, err)
+	_, err = io.ReadAll(r)
+	assert.ErrorIs(t, err, padding.ErrInvalidPadding)
+}
@@ -0,0 +1,209 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/suyuan32/knife/cryptox/symmetric/method"
+	"github.com/suyuan32/knife/cryptox/symmetric/mode"
+	"github.com/suyuan32/knife/cryptox/symmetric/padding"
+)
+
+// The transformation is the "algorithm/mode/padding" string of Java Cipher.getInstance such as
+// "AES/CBC/PKCS5Padding". The names are case-insensitive, and the registered methods and paddings are matched
+// by their names.
+
+// javaMethods is the methods of the upper case Java algorithm names.
+var javaMethods = map[string]method.MethodType{
+	"AES":                method.AES,
+	"SM4":                method.SM4,
+	"CAST5":              method.CAST5,
+	"TWOFISH":            method.Twofish,
+	"TEA":                method.TEA,
+	"XTEA":               method.XTEA,
+	"CHACHA20-POLY1305":  method.ChaCha20Poly1305,
+	"XCHACHA20-POLY1305": method.XChaCha20Poly1305,
+	"DES":                method.DES,
+	"DESEDE":             method.TripleDES,
+	"TRIPLEDES":          method.TripleDES,
+	"3DES":               method.TripleDES,
+	"BLOWFISH":           method.Blowfish,
+}
+
+// javaModes is the modes of the upper case Java mode names, zero means no mode.
+var javaModes = map[string]mode.ModeType{
+	"NONE": 0,
+	"CBC":  mode.CBC,
+	"CFB":  mode.CFB,
+	"OFB":  mode.OFB,
+	"CTR":  mode.CTR,
+	"GCM":  mode.GCM,
+	"ECB":  mode.ECB,
+	"CCM":  mode.CCM,
+	"SIV":  mode.SIV,
+}
+
+// javaPaddings is the paddings of the upper case Java padding names.
+var javaPaddings = map[string]padding.PaddingType{
+	"NOPADDING":         padding.No,
+	"PKCS5PADDING":      padding.PKCS5,
+	"PKCS7PADDING":      padding.PKCS7,
+	"ZEROBYTEPADDING":   padding.Zero,
+	"ISO10126PADDING":   padding.ISO10126,
+	"ISO10126-2PADDING": padding.ISO10126,
+	"X9.23PADDING":      padding.ANSIX923,
+	"X923PADDING":       padding.ANSIX923,
+	"ISO7816-4PADDING":  padding.ISO97971,
+	"ISO9797-1PADDING":  padding.ISO97971,
+}
+
+// javaPaddingNames is the Java names used to render the paddings.
+var javaPaddingNames = map[padding.PaddingType]string{
+	padding.No:       "NoPadding",
+	padding.PKCS5:    "PKCS5Padding",
+	padding.PKCS7:    "PKCS7Padding",
+	padding.Zero:     "ZeroBytePadding",
+	padding.ISO10126: "ISO10126Padding",
+	padding.ANSIX923: "X9.23Padding",
+	padding.ISO97971: "ISO7816-4Padding",
+}
+
+// WithTransformation set method, mode and padding from the Java transformation string such as
+// "SM4/ECB/PKCS7Padding" or "AES/GCM/NoPadding". ChaCha20-Poly1305 uses "None" as the mode.
+// The short form such as "AES" is rejected because Java uses the insecure ECB mode for it.
+func (s *CryptoS) WithTransformation(transformation string) *CryptoS {
+	m, md, p, err := parseTransformation(transformation)
+	if err != nil {
+		s.Errors = errors.Join(s.Errors, err)
+		return s
+	}
+
+	s.Method, s.Mode, s.Padding = m, md, p
+	return s
+}
+
+// Transformation returns the Java transformation string of the method, mode and padding such as
+// "AES/CBC/PKCS5Padding".
+func (s *CryptoS) Transformation() (string, error) {
+	if err := checkTransformation(s.Method, s.Mode, s.Padding); err != nil {
+		return "", err
+	}
+
+	algorithm := s.Method.String()
+	if s.Method == method.TripleDES {
+		algorithm = "DESede"
+	}
+
+	modeName := s.Mode.String()
+	if isChaCha(s.Method) {
+		modeName = "None"
+	}
+
+	paddingName, ok := javaPaddingNames[s.Padding]
+	switch {
+	case s.Padding == 0:
+		paddingName = javaPaddingNames[padding.No]
+	case !ok:
+		paddingName = s.Padding.String()
+	}
+
+	return algorithm + "/" + modeName + "/" + paddingName, nil
+}
+
+// parseTransformation returns the method, mode and padding of the Java transformation string.
+func parseTransformation(transformation string) (method.MethodType, mode.ModeType, padding.PaddingType, error) {
+	parts := strings.Split(strings.TrimSpace(transformation), "/")
+	if len(parts) != 3 {
+		return 0, 0, 0, fmt.Errorf("the transformation %q must be in the form of algorithm/mode/padding",
+			transformation)
+	}
+
+	m, ok := javaMethods[strings.ToUpper(parts[0])]
+	if !ok {
+		if m, ok = method.ByName(parts[0]); !ok {
+			return 0, 0, 0, fmt.Errorf("the algorithm %s of the transformation is not supported", parts[0])
+		}
+	}
+
+	md, ok := javaModes[strings.ToUpper(parts[1])]
+	if !ok {
+		return 0, 0, 0, fmt.Errorf("the mode %s of the transformation is not supported", parts[1])
+	}
+
+	// the mode of ChaCha20-Poly1305 is None in Java
+	if isChaCha(m) != (md == 0) {
+		if md == 0 {
+			return 0, 0, 0, fmt.Errorf("the %s method requires a mode, got None", m)
+		}
+		return 0, 0, 0, fmt.Errorf("the %s method must use the None mode, got %s", m, md)
+	}
+
+	p, ok := javaPaddings[strings.ToUpper(parts[2])]
+	if !ok {
+		if p, ok = padding.ByName(parts[2]); !ok {
+			return 0, 0, 0, fmt.Errorf("the padding %s of the transformation is not supported", parts[2])
+		}
+	}
+
+	if err := checkTransformation(m, md, p); err != nil {
+		return 0, 0, 0, err
+	}
+
+	return m, md, p, nil
+}
+
+// checkTransformation checks whether the combination of method, mode and padding is supported,
+// the mode is ignored by ChaCha20-Poly1305.
+func checkTransformation(m method.MethodType, md mode.ModeType, p padding.PaddingType) error {
+	s := CryptoS{Method: m, Mode: md, Padding: p}
+
+	if _, ok := method.Lookup(m); !ok && !isChaCha(m) && s.blockSize() == 0 {
+		return fmt.Errorf("the %s method is not supported", m)
+	}
+
+	if !isChaCha(m) {
+		if _, ok := javaModes[strings.ToUpper(md.String())]; !ok {
+			return fmt.Errorf("the mode %s is not supported", md)
+		}
+	}
+
+	if s.isAEAD() && p != 0 && p != padding.No {
+		name := md.String()
+		if isChaCha(m) {
+			name = m.String()
+		}
+		return fmt.Errorf("the %s is an AEAD and must use NoPadding, got %s", name, p)
+	}
+
+	if md == mode.SIV && m != method.AES {
+		return fmt.Errorf("the SIV mode only supports AES, got %s", m)
+	}
+
+	// the block size of registered methods is unknown without the key
+	if !isChaCha(m) && (md == mode.GCM || md == mode.CCM) && s.blockSize() != 0 && s.blockSize() != 16 {
+		return fmt.Errorf("the %s mode requires a 16 bytes block cipher, the block size of %s is %d",
+			md, m, s.blockSize())
+	}
+
+	return nil
+}
+
+// isChaCha returns true if the method is ChaCha20-Poly1305 or XChaCha20-Poly1305.
+func isChaCha(m method.MethodType) bool {
+	return m == method.ChaCha20Poly1305 || m == method.XChaCha20Poly1305
+}
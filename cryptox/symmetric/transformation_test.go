@@ -0,0 +1,170 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/suyuan32/knife/cryptox/symmetric/method"
+	"github.com/suyuan32/knife/cryptox/symmetric/mode"
+	"github.com/suyuan32/knife/cryptox/symmetric/padding"
+)
+
+func TestCryptoS_WithTransformation(t *testing.T) {
+	tests := []struct {
+		transformation string
+		method         method.MethodType
+		mode           mode.ModeType
+		padding        padding.PaddingType
+		want           string
+	}{
+		{
+			transformation: "AES/CBC/PKCS5Padding",
+			method:         method.AES,
+			mode:           mode.CBC,
+			padding:        padding.PKCS5,
+			want:           "AES/CBC/PKCS5Padding",
+		},
+		{
+			transformation: "sm4/ecb/pkcs7padding",
+			method:         method.SM4,
+			mode:           mode.ECB,
+			padding:        padding.PKCS7,
+			want:           "SM4/ECB/PKCS7Padding",
+		},
+		{
+			transformation: "AES/GCM/NoPadding",
+			method:         method.AES,
+			mode:           mode.GCM,
+			padding:        padding.No,
+			want:           "AES/GCM/NoPadding",
+		},
+		{
+			transformation: "DESede/CBC/PKCS5Padding",
+			method:         method.TripleDES,
+			mode:           mode.CBC,
+			padding:        padding.PKCS5,
+			want:           "DESede/CBC/PKCS5Padding",
+		},
+		{
+			transformation: " ChaCha20-Poly1305/None/NoPadding ",
+			method:         method.ChaCha20Poly1305,
+			padding:        padding.No,
+			want:           "ChaCha20-Poly1305/None/NoPadding",
+		},
+		{
+			transformation: "SM4/CTR/ISO10126Padding",
+			method:         method.SM4,
+			mode:           mode.CTR,
+			padding:        padding.ISO10126,
+			want:           "SM4/CTR/ISO10126Padding",
+		},
+		{
+			transformation: "Blowfish/OFB/X923Padding",
+			method:         method.Blowfish,
+			mode:           mode.OFB,
+			padding:        padding.ANSIX923,
+			want:           "Blowfish/OFB/X9.23Padding",
+		},
+		{
+			transformation: "Twofish/CFB/ISO9797-1Padding",
+			method:         method.Twofish,
+			mode:           mode.CFB,
+			padding:        padding.ISO97971,
+			want:           "Twofish/CFB/ISO7816-4Padding",
+		},
+		{
+			transformation: "AES/SIV/NoPadding",
+			method:         method.AES,
+			mode:           mode.SIV,
+			padding:        padding.No,
+			want:           "AES/SIV/NoPadding",
+		},
+	}
+
+	for _, v := range tests {
+		t.Run(v.transformation, func(t *testing.T) {
+			c := newTestCryptoS()
+			c.WithTransformation(v.transformation)
+			assert.Nil(t, c.Errors)
+			assert.Equal(t, v.method, c.Method)
+			assert.Equal(t, v.mode, c.Mode)
+			assert.Equal(t, v.padding, c.Padding)
+
+			result, err := c.Transformation()
+			assert.Nil(t, err)
+			assert.Equal(t, v.want, result)
+		})
+	}
+
+	// the default configuration without padding
+	c := newTestCryptoS()
+	result, err := c.Transformation()
+	assert.Nil(t, err)
+	assert.Equal(t, "AES/CBC/NoPadding", result)
+
+	// the mode of CryptoS is ignored by ChaCha20-Poly1305
+	c.WithMethod(method.XChaCha20Poly1305)
+	result, err = c.Transformation()
+	assert.Nil(t, err)
+	assert.Equal(t, "XChaCha20-Poly1305/None/NoPadding", result)
+
+	c.WithMethod(method.SM4).WithMode(mode.GCM).WithPadding(padding.PKCS7)
+	_, err = c.Transformation()
+	assert.EqualError(t, err, "the GCM is an AEAD and must use NoPadding, got PKCS7")
+
+	c.WithMethod(0).WithMode(mode.CBC).WithPadding(padding.PKCS7)
+	_, err = c.Transformation()
+	assert.EqualError(t, err, "the MethodType(0) method is not supported")
+
+	errorTests := map[string]string{
+		"AES":                                 "the transformation \"AES\" must be in the form of algorithm/mode/padding",
+		"RC4/CBC/PKCS5Padding":                "the algorithm RC4 of the transformation is not supported",
+		"AES/CFB8/NoPadding":                  "the mode CFB8 of the transformation is not supported",
+		"AES/CBC/OAEPPadding":                 "the padding OAEPPadding of the transformation is not supported",
+		"AES/GCM/PKCS5Padding":                "the GCM is an AEAD and must use NoPadding, got PKCS5",
+		"ChaCha20-Poly1305/GCM/NoPadding":     "the ChaCha20-Poly1305 method must use the None mode, got GCM",
+		"ChaCha20-Poly1305/None/PKCS7Padding": "the ChaCha20-Poly1305 is an AEAD and must use NoPadding, got PKCS7",
+		"AES/None/NoPadding":                  "the AES method requires a mode, got None",
+		"SM4/SIV/NoPadding":                   "the SIV mode only supports AES, got SM4",
+		"DESede/GCM/NoPadding":                "the GCM mode requires a 16 bytes block cipher, the block size of 3DES is 8",
+	}
+
+	for k, v := range errorTests {
+		c = newTestCryptoS()
+		c.WithTransformation(k)
+		assert.EqualError(t, c.Errors, v)
+		assert.Equal(t, method.AES, c.Method)
+	}
+}
+
+func TestCryptoS_Transformation_Registered(t *testing.T) {
+	p := padding.FirstRegistered + 73
+	err := padding.Register(p, "TestTransformationPadding", func(data []byte, blockSize int) ([]byte, error) {
+		return padding.PaddingPKCS7(data, blockSize), nil
+	}, padding.DePaddingPKCS7)
+	assert.Nil(t, err)
+
+	c := newTestCryptoS()
+	c.WithTransformation("SM4/CBC/TestTransformationPadding")
+	assert.Nil(t, c.Errors)
+	assert.Equal(t, p, c.Padding)
+
+	result, err := c.Transformation()
+	assert.Nil(t, err)
+	assert.Equal(t, "SM4/CBC/TestTransformationPadding", result)
+}
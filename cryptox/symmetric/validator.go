@@ -15,34 +15,185 @@
 package symmetric
 
 import (
-	"errors"
 	"fmt"
 
+	"github.com/suyuan32/knife/cryptox/keyhandle"
+	"github.com/suyuan32/knife/cryptox/symmetric/mac"
 	"github.com/suyuan32/knife/cryptox/symmetric/method"
 	"github.com/suyuan32/knife/cryptox/symmetric/mode"
 )
 
-// Validate validates the CryptoS and returns error if it does not meet the requirements.
-func (s *CryptoS) Validate(blockSize int) error {
+// ValidationError is returned when the CryptoS does not meet the requirements. Field is the invalid field such as
+// key and Constraint describes the constraint which failed.
+type ValidationError struct {
+	Field      string
+	Constraint string
+}
+
+// Error returns the message of the validation error.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Constraint)
+}
+
+// newValidationError returns a ValidationError of the field with the formatted constraint.
+func newValidationError(field, format string, args ...any) error {
+	return &ValidationError{Field: field, Constraint: fmt.Sprintf(format, args...)}
+}
+
+// Validate validates the CryptoS and returns *ValidationError if it does not meet the requirements.
+// The blockSize is the block size of the method.
+func (s *CryptoS) Validate(blockSize int) (err error) {
+	s.operate(func() { err = s.validate(blockSize) })
+	return err
+}
+
+// validate validates the CryptoS in an operation.
+func (s *CryptoS) validate(blockSize int) error {
+	if s.Method.IsInsecure() && !s.InsecureAllowed {
+		return newValidationError("method", "the %s method is insecure, call AllowInsecure to use it", s.Method)
+	}
+
+	if s.isAEAD() {
+		return s.validateAEAD()
+	}
+
+	// the key of registered methods is validated first because the block size depends on it
+	if _, ok := method.Lookup(s.Method); ok {
+		if err := s.validateKey(); err != nil {
+			return err
+		}
+	} else if s.blockSize() == 0 {
+		return newValidationError("method", "the %s method is not supported", s.Method)
+	}
+
 	switch s.Mode {
-	case mode.CBC, mode.CFB, mode.OFB:
+	case mode.CBC, mode.CFB, mode.OFB, mode.CTR:
 		if len(s.IV) != blockSize {
-			return fmt.Errorf("the IV is not the same as block size, IV size: %d, block size: %d", len(s.IV), blockSize)
+			return newValidationError("IV", "the IV size must be the same as block size %d, got %d", blockSize, len(s.IV))
+		}
+	case mode.ECB:
+		// the IV is ignored by the ECB mode
+		if !s.ECBAllowed {
+			return newValidationError("mode", "the ECB mode is insecure, call AllowECB to use it")
 		}
 	default:
+		return newValidationError("mode", "the mode %d is not supported", s.Mode)
+	}
+
+	if s.MAC != 0 {
+		if _, err := mac.HashFunc(s.MAC); err != nil {
+			return newValidationError("MAC", "%s", err)
+		}
+	}
+
+	return s.validateKey()
+}
+
+// validateAEAD validates the nonce and key of AEAD methods and modes.
+func (s *CryptoS) validateAEAD() error {
+	if s.MAC != 0 {
+		return newValidationError("MAC", "the AEAD does not need encrypt-then-MAC")
+	}
+
+	if _, ok := method.Lookup(s.Method); ok {
+		if err := s.validateKey(); err != nil {
+			return err
+		}
+	}
+
+	if s.Method != method.ChaCha20Poly1305 && s.Method != method.XChaCha20Poly1305 {
+		if size := s.blockSize(); size != 16 {
+			return newValidationError("method", "the mode requires a 16 bytes block cipher, got %d", size)
+		}
+		if s.NonceSize < 0 {
+			return newValidationError("nonce", "the nonce size must be positive, got %d", s.NonceSize)
+		}
+	}
+
+	if s.Mode == mode.SIV && s.Method != method.AES {
+		return newValidationError("method", "the SIV mode only supports AES")
+	}
+
+	if s.Mode == mode.CCM {
+		if size := s.nonceSize(); size < ccmMinNonceSize || size > ccmMaxNonceSize {
+			return newValidationError("nonce", "the nonce size of CCM mode must be between %d and %d, got %d",
+				ccmMinNonceSize, ccmMaxNonceSize, size)
+		}
+		if !isValidCCMTagSize(s.tagSize()) {
+			return newValidationError("tag", "the tag size of CCM mode must be 4, 6, 8, 10, 12, 14 or 16, got %d",
+				s.tagSize())
+		}
+	}
+
+	if len(s.IV) != s.nonceSize() {
+		return newValidationError("nonce", "the nonce size must be %d, got %d", s.nonceSize(), len(s.IV))
+	}
+
+	return s.validateKey()
+}
+
+// validateKey validates the key length of the method.
+func (s *CryptoS) validateKey() error {
+	// the key of the handle is only nil if it has been destroyed, Destroyed is not called because it would lock
+	// the handle again in the operation
+	key := s.key()
+	if s.KeyHandle != nil && key == nil {
+		return newValidationError("key", "%s", keyhandle.ErrDestroyed)
+	}
+
+	if len(key) == 0 {
+		return newValidationError("key", "the key cannot be empty")
+	}
+
+	// the key size of Blowfish is variable
+	if s.Method == method.Blowfish {
+		if len(key) < 4 || len(key) > 56 {
+			return newValidationError("key", "the key size of Blowfish must be between 4 and 56, got %d", len(key))
+		}
 		return nil
 	}
 
-	if len(s.Key) == 0 {
-		return errors.New("the key cannot be empty")
+	// the key of registered methods is validated by their factories
+	if factory, ok := method.Lookup(s.Method); ok {
+		if _, err := s.registeredBlock(factory); err != nil {
+			return newValidationError("key", "invalid key for %s: %s", s.Method, err)
+		}
+		return nil
 	}
 
-	switch s.Method {
-	case method.AES:
-		if len(s.Key) != 16 && len(s.Key) != 24 && len(s.Key) != 32 {
-			return errors.New("the length of key of AES can only be 16, 24, 32 ")
+	sizes := s.keySizes()
+	if sizes == nil {
+		return newValidationError("method", "the %s method is not supported", s.Method)
+	}
+
+	for _, v := range sizes {
+		if len(key) == v {
+			return nil
 		}
 	}
 
+	return newValidationError("key", "the key size of %s can only be %v, got %d", s.Method, sizes, len(key))
+}
+
+// keySizes returns the valid key sizes of the method, it returns nil if the method is not supported.
+func (s *CryptoS) keySizes() []int {
+	// the key of SIV mode is split into the MAC key and the encryption key
+	if s.Mode == mode.SIV && s.Method == method.AES {
+		return []int{32, 48, 64}
+	}
+
+	switch s.Method {
+	case method.AES, method.Twofish:
+		return []int{16, 24, 32}
+	case method.SM4, method.CAST5, method.TEA, method.XTEA:
+		return []int{16}
+	case method.ChaCha20Poly1305, method.XChaCha20Poly1305:
+		return []int{32}
+	case method.DES:
+		return []int{8}
+	case method.TripleDES:
+		return []int{16, 24}
+	}
+
 	return nil
 }
@@ -0,0 +1,88 @@
+// Copyright 2023 The Ryan SU Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symmetric
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/suyuan32/knife/cryptox/symmetric/mac"
+	"github.com/suyuan32/knife/cryptox/symmetric/method"
+	"github.com/suyuan32/knife/cryptox/symmetric/mode"
+	"github.com/suyuan32/knife/cryptox/symmetric/padding"
+)
+
+func TestCryptoS_Validate_Fields(t *testing.T) {
+	key := func(size int) []byte { return bytes.Repeat([]byte{'k'}, size) }
+
+	tests := []struct {
+		name   string
+		method method.MethodType
+		mode   mode.ModeType
+		key    []byte
+		iv     []byte
+		mac    mac.MACType
+		field  string
+	}{
+		{name: "AES-CBC", method: method.AES, mode: mode.CBC, key: key(24), iv: key(16)},
+		{name: "AES invalid key", method: method.AES, mode: mode.CBC, key: key(20), iv: key(16), field: "key"},
+		{name: "CTR empty key", method: method.AES, mode: mode.CTR, iv: key(16), field: "key"},
+		{name: "CTR invalid IV", method: method.AES, mode: mode.CTR, key: key(16), iv: key(8), field: "IV"},
+		{name: "SM4", method: method.SM4, mode: mode.CFB, key: key(16), iv: key(16)},
+		{name: "SM4 invalid key", method: method.SM4, mode: mode.CFB, key: key(32), iv: key(16), field: "key"},
+		{name: "CAST5", method: method.CAST5, mode: mode.OFB, key: key(16), iv: key(8)},
+		{name: "CAST5 invalid key", method: method.CAST5, mode: mode.OFB, key: key(10), iv: key(8), field: "key"},
+		{name: "Twofish", method: method.Twofish, mode: mode.CBC, key: key(32), iv: key(16)},
+		{name: "Twofish invalid key", method: method.Twofish, mode: mode.CBC, key: key(8), iv: key(16), field: "key"},
+		{name: "TEA invalid IV", method: method.TEA, mode: mode.CBC, key: key(16), iv: key(16), field: "IV"},
+		{name: "XTEA invalid key", method: method.XTEA, mode: mode.CTR, key: key(24), iv: key(8), field: "key"},
+		{name: "ECB not allowed", method: method.AES, mode: mode.ECB, key: key(16), field: "mode"},
+		{name: "unknown mode", method: method.AES, mode: mode.ModeType(100), key: key(16), iv: key(16), field: "mode"},
+		{name: "unknown method", method: method.MethodType(100), mode: mode.CBC, key: key(16), iv: key(16), field: "method"},
+		{name: "unknown MAC", method: method.AES, mode: mode.CBC, key: key(16), iv: key(16), mac: mac.MACType(100), field: "MAC"},
+		{name: "GCM", method: method.AES, mode: mode.GCM, key: key(16), iv: key(12)},
+		{name: "GCM invalid nonce", method: method.AES, mode: mode.GCM, key: key(16), iv: key(16), field: "nonce"},
+		{name: "GCM with 8 bytes block", method: method.TEA, mode: mode.GCM, key: key(16), iv: key(12), field: "method"},
+		{name: "GCM with MAC", method: method.AES, mode: mode.GCM, key: key(16), iv: key(12), mac: mac.HMACSHA256, field: "MAC"},
+		{name: "ChaCha20-Poly1305 invalid key", method: method.ChaCha20Poly1305, key: key(16), iv: key(12), field: "key"},
+		{name: "XChaCha20-Poly1305 invalid nonce", method: method.XChaCha20Poly1305, key: key(32), iv: key(12), field: "nonce"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCryptoS()
+			c.WithMethod(tt.method).WithMode(tt.mode).WithKey(tt.key).WithIV(tt.iv).WithMAC(tt.mac).
+				WithPadding(padding.PKCS7)
+
+			err := c.Validate(c.blockSize())
+			if tt.field == "" {
+				assert.Nil(t, err)
+				return
+			}
+
+			var validationErr *ValidationError
+			assert.True(t, errors.As(err, &validationErr))
+			assert.Equal(t, tt.field, validationErr.Field)
+
+			// the error is reported by Encrypt before the cipher is constructed
+			c.InputFromString("hello").Encrypt()
+			assert.True(t, errors.As(c.Errors, &validationErr))
+			assert.Equal(t, tt.field, validationErr.Field)
+		})
+	}
+}